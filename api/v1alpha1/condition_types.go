@@ -0,0 +1,45 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// Condition types of the Terraform object status
+const (
+	// ConditionReady indicates the run was applied successfully and is up to date with the spec
+	ConditionReady string = "Ready"
+	// ConditionReconciling indicates the controller is working towards the desired state,
+	// only present while it's true
+	ConditionReconciling string = "Reconciling"
+	// ConditionStalled indicates the run failed and needs a change to make progress,
+	// only present while it's true
+	ConditionStalled string = "Stalled"
+	// ConditionDependenciesReady indicates all the dependencies of the run are completed
+	ConditionDependenciesReady string = "DependenciesReady"
+	// ConditionPlanned indicates the changes of the run were planned, only set with a manual approval
+	ConditionPlanned string = "Planned"
+	// ConditionApplied indicates the changes of the run were applied
+	ConditionApplied string = "Applied"
+)
+
+// Condition reasons of the Terraform object status
+const (
+	ReasonSucceeded          string = "Succeeded"
+	ReasonFailed             string = "Failed"
+	ReasonProgressing        string = "Progressing"
+	ReasonDependencyNotReady string = "DependencyNotReady"
+	ReasonAwaitingApproval   string = "AwaitingApproval"
+	ReasonDeleting           string = "Deleting"
+)
@@ -18,22 +18,85 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // TerraformFinalizer is the finalizer name
 const TerraformFinalizer string = "finalizers.terraform-operator.io"
 
+// Annotations used to approve a planned workflow/run
+const (
+	// ApproveRunAnnotation must be set to the ID of the planned run to approve it
+	ApproveRunAnnotation string = "run.terraform-operator.io/approve"
+	// ApproverAnnotation is set by the mutating webhook to the authenticated user who approved the run
+	ApproverAnnotation string = "run.terraform-operator.io/approved-by"
+)
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // Module holds the Terraform module source and version information
 type Module struct {
-	// module source, must be a valid Terraform module source
-	Source string `json:"source"`
+	// module source, must be a valid Terraform module source. Required unless sourceFrom is set
+	// +optional
+	Source string `json:"source,omitempty"`
 	// module version
 	// +optional
 	Version string `json:"version,omitempty"`
+	// The files of a local module read from configmaps & secrets, the job unpacks them
+	// in a directory of the Terraform project used as the module source
+	// +optional
+	SourceFrom *ModuleSourceFrom `json:"sourceFrom,omitempty"`
+	// The typed provider configurations passed to the module. Defaults to all the typed providers,
+	// passed with their own names
+	// +optional
+	Providers []ModuleProvider `json:"providers,omitempty"`
+}
+
+// ModuleSourceFrom holds the sources (configmaps, secrets or a gzipped tarball) of the files of a local module
+type ModuleSourceFrom struct {
+	// The configmaps whose keys are the files of the module, a large module can be split across several configmaps
+	// +optional
+	ConfigMaps []corev1.LocalObjectReference `json:"configMaps,omitempty"`
+	// The secrets whose keys are the files of the module
+	// +optional
+	Secrets []corev1.LocalObjectReference `json:"secrets,omitempty"`
+	// The parts of a gzipped tarball of the module, each part is a key of a configmap or a secret.
+	// The parts are concatenated in order before the tarball is extracted
+	// +optional
+	Archive []ExtraFileSource `json:"archive,omitempty"`
+}
+
+// ModuleCall holds a named call of a Terraform module
+type ModuleCall struct {
+	// The name of the module call, its outputs are addressed as `module.<name>.<output>`
+	Name string `json:"name"`
+	// The module information (source & version)
+	Module `json:",inline"`
+	// The inputs of the module
+	// +optional
+	Inputs []ModuleInput `json:"inputs,omitempty"`
+}
+
+// ModuleInput holds an input of a module call, set from a variable or the output of another module call
+type ModuleInput struct {
+	// The name of the module variable
+	Name string `json:"name"`
+	// The key of the variable (in spec.variables) passed to the input
+	// +optional
+	Variable string `json:"variable,omitempty"`
+	// The output of another module call passed to the input
+	// +optional
+	ModuleOutput *ModuleOutputRef `json:"moduleOutput,omitempty"`
+}
+
+// ModuleOutputRef references the output of a module call
+type ModuleOutputRef struct {
+	// The name of the module call
+	Module string `json:"module"`
+	// The output name as defined in the Terraform module
+	Output string `json:"output"`
 }
 
 // VariableFile holds the information of the Terraform variable files to include
@@ -45,11 +108,37 @@ type VariableFile struct {
 	ValueFrom *corev1.VolumeSource `json:"valueFrom"`
 }
 
+// ExtraFile holds an additional Terraform file of the project, next to the generated module call
+type ExtraFile struct {
+	// The name of the file in the Terraform project, ending with `.tf` or `.tf.json`
+	Name string `json:"name"`
+	// The content of the file
+	// +optional
+	Content string `json:"content,omitempty"`
+	// The content of the file from a key source (secret or configmap)
+	// +optional
+	ValueFrom *ExtraFileSource `json:"valueFrom,omitempty"`
+}
+
+// ExtraFileSource holds the key source (secret or configmap) of an additional Terraform file
+type ExtraFileSource struct {
+	// Selects a key of a configmap
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Selects a key of a secret
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
 // TerraformDependencyRef holds the information of the Terraform dependency name and key for the module
 // to use as a variable
 type TerraformDependencyRef struct {
 	Name string `json:"name"`
-	Key  string `json:"key"`
+	// The namespace of the dependency, defaults to the namespace of the workflow/run.
+	// A dependency of another namespace must grant its outputs to the namespace through `outputGrants`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	Key       string `json:"key"`
 }
 
 // Variable holds the information of the Terraform variable
@@ -68,6 +157,19 @@ type Variable struct {
 	// DependencyRef denotes if this variable should be fetched from the output of a dependency
 	// +optional
 	DependencyRef *TerraformDependencyRef `json:"dependencyRef,omitempty"`
+	// The structured value of the variable (e.g. a list, a map or an object),
+	// passed to Terraform in a generated variables file
+	// +optional
+	JSONValue *apiextensionsv1.JSON `json:"jsonValue,omitempty"`
+	// The Terraform type constraint of the variable, e.g. `map(string)`
+	// +optional
+	Type string `json:"type,omitempty"`
+	// Indicates whether the variable is sensitive, Terraform then redacts its value from the plan and the logs
+	// +optional
+	Sensitive bool `json:"sensitive,omitempty"`
+	// Indicates whether the variable can be set to null. Defaults to `true`
+	// +optional
+	Nullable *bool `json:"nullable,omitempty"`
 }
 
 // Output holds the information of the Terraform output information
@@ -79,6 +181,15 @@ type Output struct {
 	// The output name as defined in the source Terraform module
 	// +optional
 	ModuleOutputName string `json:"moduleOutputName"`
+	// The name of the module call the output is read from. Defaults to `operator`, the call of the spec module
+	// +optional
+	Module string `json:"module,omitempty"`
+}
+
+// OutputGrant allows the workflows/runs of another namespace to use the outputs as variables
+type OutputGrant struct {
+	// The namespace the outputs are granted to
+	Namespace string `json:"namespace"`
 }
 
 // DependsOn holds the information of the Terraform dependency
@@ -92,10 +203,306 @@ type DependsOn struct {
 
 // GitSSHKey holds the information of the Git SSH key
 type GitSSHKey struct {
-	// The source of the value where the private SSH key exist
-	ValueFrom *corev1.VolumeSource `json:"valueFrom"`
+	// The source of the value where the private SSH key exist, the key is mounted as `id_rsa`
+	// +optional
+	ValueFrom *corev1.VolumeSource `json:"valueFrom,omitempty"`
+	// Additional private SSH keys, e.g. an `id_ed25519` key or a key per git host
+	// +optional
+	Keys []GitSSHKeyFile `json:"keys,omitempty"`
+	// An ssh_config file from a key source (secret or configmap), e.g. to select the key and user of each git host
+	// +optional
+	ConfigFrom *ExtraFileSource `json:"configFrom,omitempty"`
+	// A known_hosts file from a key source (secret or configmap), used instead of the known hosts of the operator
+	// +optional
+	KnownHostsFrom *ExtraFileSource `json:"knownHostsFrom,omitempty"`
+}
+
+// GitSSHKeyFile holds a private SSH key read from a secret
+type GitSSHKeyFile struct {
+	// The file name of the key, e.g. `id_ed25519`, the key can be referenced by the ssh_config
+	// as `/terraform/ssh/<name>`
+	Name string `json:"name"`
+	// Selects the key of a secret holding the private SSH key
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// GitHTTPSCredential holds the HTTPS credentials (username and password or token) of a git host
+type GitHTTPSCredential struct {
+	// The URL the credentials are used for, e.g. `https://github.com` or `https://gitlab.example.com/platform`
+	URL string `json:"url"`
+	// The username
+	// +optional
+	Username string `json:"username,omitempty"`
+	// Selects the key of a secret holding the username
+	// +optional
+	UsernameFrom *corev1.SecretKeySelector `json:"usernameFrom,omitempty"`
+	// Selects the key of a secret holding the password or token
+	PasswordFrom *corev1.SecretKeySelector `json:"passwordFrom"`
+}
+
+// CLIConfig holds the Terraform CLI configuration of the workflow/run
+type CLIConfig struct {
+	// The API tokens of the private registries, passed to Terraform as `TF_TOKEN_<host>` environment variables
+	// +optional
+	Credentials []CLICredential `json:"credentials,omitempty"`
+	// The provider installation methods, in the order they are tried. Replaces the default CLI configuration of the operator
+	// +optional
+	ProviderInstallation []ProviderInstallationMethod `json:"providerInstallation,omitempty"`
+}
+
+// CLICredential holds the API token of a private registry (or of a Terraform Cloud/Enterprise host)
+type CLICredential struct {
+	// The hostname of the registry, e.g. `registry.example.com`
+	Host string `json:"host"`
+	// Selects the key of a secret holding the API token
+	TokenFrom *corev1.SecretKeySelector `json:"tokenFrom"`
+}
+
+// ProviderInstallationMethod holds a provider installation method, exactly one of networkMirror, filesystemMirror or direct is set
+type ProviderInstallationMethod struct {
+	// The URL of a provider network mirror, e.g. `https://mirror.example.com/providers/`
+	// +optional
+	NetworkMirror string `json:"networkMirror,omitempty"`
+	// The path of a provider filesystem mirror in the runner container, e.g. a directory of the providers cache
+	// +optional
+	FilesystemMirror string `json:"filesystemMirror,omitempty"`
+	// Installs the providers from their origin registries
+	// +optional
+	Direct bool `json:"direct,omitempty"`
+	// The providers installed with the method, e.g. `registry.example.com/*/*`. Defaults to all the providers
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// The providers that are not installed with the method
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// BackendType is the type of a Terraform backend
+type BackendType string
+
+// typed backend types
+const (
+	// BackendKubernetes stores the state in a secret
+	BackendKubernetes BackendType = "kubernetes"
+	// BackendS3 stores the state in an S3 bucket
+	BackendS3 BackendType = "s3"
+	// BackendGCS stores the state in a Google Cloud Storage bucket
+	BackendGCS BackendType = "gcs"
+	// BackendAzureRM stores the state in an Azure Storage blob container
+	BackendAzureRM BackendType = "azurerm"
+	// BackendHTTP stores the state with a REST client
+	BackendHTTP BackendType = "http"
+	// BackendLocal stores the state in a persistent volume claim
+	BackendLocal BackendType = "local"
+)
+
+// TypedBackend holds a typed Terraform backend configuration, its sensitive settings are read from secrets
+type TypedBackend struct {
+	// The type of the backend
+	// +kubebuilder:validation:Enum=kubernetes;s3;gcs;azurerm;http;local
+	// +optional
+	Type BackendType `json:"type,omitempty"`
+	// The non-sensitive settings of the backend, e.g. the `bucket` and `region` of the s3 backend
+	// +optional
+	Settings map[string]string `json:"settings,omitempty"`
+	// Secret keys holding partial backend configurations with the sensitive settings (e.g. `access_key = "..."`),
+	// passed to terraform init as -backend-config files
+	// +optional
+	ConfigFrom []corev1.SecretKeySelector `json:"configFrom,omitempty"`
+	// The claim of the volume holding the state of the local backend
+	// +optional
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+}
+
+// Provider holds a typed Terraform provider, its requirement and its configuration
+type Provider struct {
+	// The local name of the provider, e.g. `aws`
+	Name string `json:"name"`
+	// The source address of the provider, e.g. `hashicorp/aws`
+	// +optional
+	Source string `json:"source,omitempty"`
+	// The version constraint of the provider, e.g. `~> 5.0`
+	// +optional
+	Version string `json:"version,omitempty"`
+	// The alias of the provider configuration, e.g. `us_east_1`. The configuration is addressed as `<name>.<alias>`
+	// +optional
+	Alias string `json:"alias,omitempty"`
+	// The arguments of the provider configuration as a JSON object, nested blocks are objects
+	// (e.g. `{"region": "us-east-1", "assume_role": {"role_arn": "..."}}`)
+	// +optional
+	Config *apiextensionsv1.JSON `json:"config,omitempty"`
+	// The arguments of the provider configuration read from secrets, passed to Terraform as sensitive variables
+	// +optional
+	ConfigFrom []ProviderArgumentSource `json:"configFrom,omitempty"`
+}
+
+// ProviderArgumentSource holds an argument of a provider configuration read from a secret
+type ProviderArgumentSource struct {
+	// The name of the argument, e.g. `access_key`
+	Name string `json:"name"`
+	// The secret key holding the value of the argument
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// ModuleProvider passes a typed provider configuration to a module
+type ModuleProvider struct {
+	// The provider configuration expected by the module, e.g. `aws` or `aws.primary`
+	Name string `json:"name"`
+	// The typed provider configuration passed to the module, e.g. `aws.us_east_1`
+	Provider string `json:"provider"`
+}
+
+// Runner holds the customizations of the runner jobs of the workflow/run
+type Runner struct {
+	// A strategic merge patch of a pod template merged into the pods of the runner jobs. It can only set the
+	// annotations, labels, nodeSelector, tolerations, affinity, securityContext, priorityClassName and volumes of
+	// the pods, and the resources, securityContext, env and volumeMounts of the `terraform` runner container
+	// +kubebuilder:validation:Type=object
+	// +optional
+	PodTemplate *apiextensionsv1.JSON `json:"podTemplate,omitempty"`
+}
+
+// RunnerServiceAccount holds the service account generated for the runner jobs of a workflow/run
+type RunnerServiceAccount struct {
+	// The annotations of the service account binding it to a cloud identity, e.g. `eks.amazonaws.com/role-arn` (EKS),
+	// `iam.gke.io/gcp-service-account` (GKE) or `azure.workload.identity/client-id` (Azure)
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ServiceAccountToken holds a service account token projected in the runner container
+type ServiceAccountToken struct {
+	// The file name of the token in the `/var/run/secrets/tokens` directory
+	Path string `json:"path"`
+	// The audience of the token, e.g. `sts.amazonaws.com`
+	Audience string `json:"audience"`
+	// The requested validity of the token in seconds. Defaults to 1 hour
+	// +kubebuilder:validation:Minimum=600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
 }
 
+// EngineType is the command line tool running a workflow/run
+type EngineType string
+
+// workflow/run engines
+const (
+	// EngineTerraform runs the workflow/run with Terraform
+	EngineTerraform EngineType = "terraform"
+	// EngineOpenTofu runs the workflow/run with OpenTofu
+	EngineOpenTofu EngineType = "opentofu"
+	// EngineTerragrunt runs the workflow/run with Terragrunt wrapping Terraform
+	EngineTerragrunt EngineType = "terragrunt"
+)
+
+// Engine holds the engine running the workflow/run and its version
+type Engine struct {
+	// The engine running the workflow/run. Defaults to `terraform`
+	// +kubebuilder:validation:Enum=terraform;opentofu;terragrunt
+	// +optional
+	Type EngineType `json:"type,omitempty"`
+	// The version of the engine installed by the runner, e.g. `1.8.2`. Defaults to terraformVersion for Terraform,
+	// Terragrunt wraps Terraform at terraformVersion
+	// +optional
+	Version string `json:"version,omitempty"`
+	// The version constraint of the engine, e.g. `>= 1.8, < 2.0`. Defaults to `~> <version>`
+	// +optional
+	VersionConstraint string `json:"versionConstraint,omitempty"`
+	// The state and plan encryption of OpenTofu
+	// +optional
+	Encryption *EngineEncryption `json:"encryption,omitempty"`
+}
+
+// EngineEncryption holds the state and plan encryption of OpenTofu
+type EngineEncryption struct {
+	// The secret key holding the encryption configuration (HCL or JSON), i.e. its key providers, methods and
+	// the encryption of the state and plan, passed to OpenTofu in TF_ENCRYPTION
+	ConfigFrom corev1.SecretKeySelector `json:"configFrom"`
+}
+
+// ApprovalMode defines whether a workflow/run is applied right away or waits for an approval
+type ApprovalMode string
+
+// workflow/run approval modes
+const (
+	ApprovalAuto   ApprovalMode = "Auto"
+	ApprovalManual ApprovalMode = "Manual"
+)
+
+// Approval holds the information of the approval given to a planned workflow/run
+type Approval struct {
+	// The ID of the approved run
+	RunID string `json:"runId"`
+	// The identity of whoever approved the run
+	ApprovedBy string `json:"approvedBy"`
+	// The time the run was approved
+	// +optional
+	ApprovalTime string `json:"approvalTime,omitempty"`
+}
+
+// DriftDetection holds the configuration of the periodic drift detection of a workflow/run
+type DriftDetection struct {
+	// The interval at which the completed run is checked for drift, e.g. `6h`
+	Interval metav1.Duration `json:"interval"`
+	// Indicates whether a new run should be submitted to remediate the detected drift
+	// +optional
+	AutoRemediate bool `json:"autoRemediate,omitempty"`
+}
+
+// Timeouts holds the timeouts of the runner jobs of a workflow/run, a run that exceeds one of them fails
+type Timeouts struct {
+	// The maximum duration of the init container copying the module and the extra files into the project, e.g. `5m`,
+	// it doesn't cover terraform init which runs in the runner container
+	// +optional
+	InitContainer *metav1.Duration `json:"initContainer,omitempty"`
+	// The deadline of a plan job, the plan of a manual approval or a drift check, from its creation to its end
+	// including its pending pods, its init container, terraform init and its retries, e.g. `30m`
+	// +optional
+	Plan *metav1.Duration `json:"plan,omitempty"`
+	// The deadline of an apply or destroy job, from its creation to its end including its pending pods, its init
+	// container, terraform init & plan and its retries, e.g. `2h`
+	// +optional
+	Apply *metav1.Duration `json:"apply,omitempty"`
+	// The maximum duration the pod of a runner job can stay pending before it starts, e.g. unschedulable
+	// or failing to pull its image, e.g. `10m`
+	// +optional
+	Pending *metav1.Duration `json:"pending,omitempty"`
+}
+
+// DriftStatus is the result of a drift check
+type DriftStatus string
+
+// drift check statuses
+const (
+	DriftChecking    DriftStatus = "Checking"
+	DriftInSync      DriftStatus = "InSync"
+	DriftDrifted     DriftStatus = "Drifted"
+	DriftCheckFailed DriftStatus = "CheckFailed"
+)
+
+// DriftDetectionStatus holds the information of the last drift check
+type DriftDetectionStatus struct {
+	// The ID of the last drift check
+	CheckID string `json:"checkId"`
+	// The status of the last drift check
+	Status DriftStatus `json:"status"`
+	// The time the last drift check finished
+	// +optional
+	LastCheckTime string `json:"lastCheckTime,omitempty"`
+	// The addresses of the resources that changed outside of Terraform
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+}
+
+// DeletionPolicy defines what happens to the infrastructure when the Terraform object is deleted
+type DeletionPolicy string
+
+// Terraform object deletion policies
+const (
+	DeletionPolicyDestroy DeletionPolicy = "Destroy"
+	DeletionPolicyOrphan  DeletionPolicy = "Orphan"
+)
+
 // TerraformRunStatus is the status of the workflow/run
 type TerraformRunStatus string
 
@@ -107,17 +514,46 @@ const (
 	RunFailed               TerraformRunStatus = "Failed"
 	RunWaitingForDependency TerraformRunStatus = "WaitingForDependency"
 	RunDeleted              TerraformRunStatus = "Deleted"
+	RunAwaitingApproval     TerraformRunStatus = "AwaitingApproval"
+	RunDeleting             TerraformRunStatus = "Deleting"
+)
+
+// RunTrigger is the reason a workflow/run was submitted
+type RunTrigger string
+
+// workflow/run triggers
+const (
+	TriggerCreated           RunTrigger = "Created"
+	TriggerUpdated           RunTrigger = "Updated"
+	TriggerDriftRemediation  RunTrigger = "DriftRemediation"
+	TriggerDependencyChanged RunTrigger = "DependencyChanged"
+	TriggerDeletion          RunTrigger = "Deletion"
 )
 
 // PreviousRunStatus stores the previous workflows/runs information
 // in case the current workflow/run object was modified
 type PreviousRunStatus struct {
-	// Attribute name in module
+	// The ID of the run
 	// +optional
 	RunID string `json:"id"`
-	// Value
+	// The last status of the run
 	// +optional
 	Status TerraformRunStatus `json:"status"`
+	// The reason the run was submitted
+	// +optional
+	Trigger RunTrigger `json:"trigger,omitempty"`
+	// The generation of the Terraform object the run was submitted for
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+	// The time the run started
+	// +optional
+	StartedTime string `json:"startTime,omitempty"`
+	// The time the run completed or failed
+	// +optional
+	CompletionTime string `json:"completionTime,omitempty"`
+	// The SHA-256 hash of the Terraform module rendered for the run
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
 }
 
 // TerraformSpec defines the desired state of Terraform object
@@ -125,16 +561,36 @@ type TerraformSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// The terraform version to use
-	TerraformVersion string `json:"terraformVersion"`
-	// The module information (source & version)
-	Module Module `json:"module"`
-	// A custom terraform backend configuration
+	// The terraform version to use. Defaults to the operator's default version if one is configured
+	// +optional
+	TerraformVersion string `json:"terraformVersion,omitempty"`
+	// The engine running the workflow/run (Terraform, OpenTofu or Terragrunt) and its version.
+	// Defaults to Terraform at terraformVersion
+	// +optional
+	Engine *Engine `json:"engine,omitempty"`
+	// The module information (source & version), the module is called as `module.operator`
+	// with all the variables as inputs. Required unless modules are set
+	// +optional
+	Module *Module `json:"module,omitempty"`
+	// Additional named module calls, their inputs are wired explicitly
+	// from the variables or the outputs of the other module calls
+	// +optional
+	Modules []ModuleCall `json:"modules,omitempty"`
+	// A custom terraform backend configuration. Defaults to the `kubernetes` backend
+	// storing the state in a secret in the namespace of the object
 	// +optional
 	Backend string `json:"backend,omitempty"`
+	// A typed terraform backend configuration whose sensitive settings are read from secrets,
+	// can't be combined with backend
+	// +optional
+	TypedBackend *TypedBackend `json:"typedBackend,omitempty"`
 	// A custom terraform providers configuration
 	// +optional
 	ProvidersConfig string `json:"providersConfig,omitempty"`
+	// Typed terraform providers added to the required providers and configured from the spec,
+	// can't be combined with providersConfig
+	// +optional
+	TypedProviders []Provider `json:"typedProviders,omitempty"`
 	// A name of a PVC to be passed to terraform to cache providers
 	// +optional
 	ProvidersCache *corev1.VolumeSource `json:"providersCache,omitempty"`
@@ -144,27 +600,88 @@ type TerraformSpec struct {
 	// A list of dependencies on other Terraform runs
 	// +optional
 	DependsOn []*DependsOn `json:"dependsOn,omitempty"`
+	// Indicates whether a new workflow/run is submitted when the outputs it uses from
+	// its dependencies change after it completed
+	// +optional
+	RerunOnDependencyChange bool `json:"rerunOnDependencyChange,omitempty"`
 	// Variables as inputs to the Terraform module
 	// +optional
 	Variables []Variable `json:"variables,omitempty"`
 	// Terraform variable files
 	// +optional
 	VariableFiles []VariableFile `json:"variableFiles,omitempty"`
+	// Additional Terraform files (e.g. locals, data sources, moved or import blocks)
+	// added to the project next to the generated module call
+	// +optional
+	ExtraFiles []ExtraFile `json:"extraFiles,omitempty"`
 	// Terraform outputs will be written to a Kubernetes secret
 	// +optional
 	Outputs []*Output `json:"outputs,omitempty"`
+	// The namespaces whose workflows/runs can use the outputs as variables,
+	// workflows/runs of the same namespace don't need a grant
+	// +optional
+	OutputGrants []OutputGrant `json:"outputGrants,omitempty"`
 	// Indicates whether a destroy job should run
 	// +optional
 	Destroy bool `json:"destroy,omitempty"`
+	// Indicates whether the infrastructure is destroyed (Destroy) or left in place (Orphan)
+	// when the Terraform object is deleted. Defaults to `Orphan`
+	// +kubebuilder:validation:Enum=Destroy;Orphan
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 	// Indicates whether to keep the jobs/pods after the run is successful/completed
 	// +optional
 	DeleteCompletedJobs bool `json:"deleteCompletedJobs,omitempty"`
-	// A retry limit to be set on the Job as a backOffLimit
+	// A retry limit to be set on the Job as a backOffLimit. Defaults to `0`
+	// +kubebuilder:default=0
 	// +optional
 	RetryLimit int32 `json:"retryLimit,omitempty"`
 	// An SSH key to be able to pull modules from private git repositories
 	// +optional
 	GitSSHKey *GitSSHKey `json:"gitSSHKey,omitempty"`
+	// HTTPS credentials (basic auth or tokens) to be able to pull modules from private git repositories,
+	// passed to git by a credential helper
+	// +optional
+	GitHTTPSCredentials []GitHTTPSCredential `json:"gitHTTPSCredentials,omitempty"`
+	// The Terraform CLI configuration (private registry credentials and provider installation).
+	// Defaults to the CLI configuration of the operator if one is configured
+	// +optional
+	CLIConfig *CLIConfig `json:"cliConfig,omitempty"`
+	// Customizations of the runner jobs, e.g. their pod template
+	// +optional
+	Runner *Runner `json:"runner,omitempty"`
+	// The name of an existing service account the runner jobs run as instead of the one generated for the
+	// workflow/run, the role of the workflow/run is bound to it. Can't be combined with serviceAccount
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Customizes the service account generated for the runner jobs of the workflow/run, e.g. to bind the runs
+	// to their own cloud identity
+	// +optional
+	ServiceAccount *RunnerServiceAccount `json:"serviceAccount,omitempty"`
+	// Service account tokens projected in the runner container, e.g. for a cloud workload identity federation
+	// +optional
+	ServiceAccountTokens []ServiceAccountToken `json:"serviceAccountTokens,omitempty"`
+	// Grants the runner the permission to list the secrets of the namespace, needed by the Kubernetes backend keeping
+	// the state in the namespace of the workflow/run to select a non-default workspace. Listing returns the data of
+	// the secrets, so the runner can read all the secrets of the namespace. Defaults to `false`
+	// +optional
+	AllowSecretList bool `json:"allowSecretList,omitempty"`
+	// Indicates whether a run is applied right away (Auto) or only planned
+	// and applied once approved (Manual). Defaults to `Auto`
+	// +kubebuilder:validation:Enum=Auto;Manual
+	// +optional
+	ApprovalMode ApprovalMode `json:"approvalMode,omitempty"`
+	// Periodically checks the completed run for drift with a refresh-only plan
+	// +optional
+	DriftDetection *DriftDetection `json:"driftDetection,omitempty"`
+	// The deadlines of the plan & apply jobs of the runs and the timeouts of their init containers and pending pods, no timeout applies unless set
+	// +optional
+	Timeouts *Timeouts `json:"timeouts,omitempty"`
+	// The number of runs to keep in the status history. Defaults to `10`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	// +optional
+	HistoryLimit int32 `json:"historyLimit,omitempty"`
 }
 
 // TerraformStatus defines the observed state of Terraform
@@ -172,22 +689,41 @@ type TerraformStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	RunID              string             `json:"currentRunId"`
-	PreviousRunID      string             `json:"previousRunId,omitempty"`
-	OutputSecretName   string             `json:"outputSecretName,omitempty"`
-	ObservedGeneration int64              `json:"observedGeneration"`
-	RunStatus          TerraformRunStatus `json:"runStatus"`
-	Message            string             `json:"message,omitempty"`
-	StartedTime        string             `json:"startTime,omitempty"`
-	CompletionTime     string             `json:"completionTime,omitempty"`
+	RunID              string                `json:"currentRunId"`
+	PreviousRunID      string                `json:"previousRunId,omitempty"`
+	OutputSecretName   string                `json:"outputSecretName,omitempty"`
+	ObservedGeneration int64                 `json:"observedGeneration"`
+	RunStatus          TerraformRunStatus    `json:"runStatus"`
+	Message            string                `json:"message,omitempty"`
+	StartedTime        string                `json:"startTime,omitempty"`
+	CompletionTime     string                `json:"completionTime,omitempty"`
+	Approval           *Approval             `json:"approval,omitempty"`
+	Drift              *DriftDetectionStatus `json:"drift,omitempty"`
+
+	// The SHA-256 hash of the dependency outputs used by the current run
+	// +optional
+	DependencyOutputsHash string `json:"dependencyOutputsHash,omitempty"`
+
+	// The latest runs, the current one first, bounded by the history limit
+	// +optional
+	History []PreviousRunStatus `json:"history,omitempty"`
+
+	// Conditions of the Terraform object following the Kubernetes API conventions
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 
 // Terraform is the Schema for the terraforms API
 // +kubebuilder:resource:shortName=tf,path=terraforms
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.runStatus"
+// +kubebuilder:printcolumn:name="Drift",type="string",JSONPath=".status.drift.status",priority=1
 // +kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".status.outputSecretName"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type Terraform struct {
@@ -0,0 +1,36 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/mutate-run-terraform-operator-io-v1alpha1-terraform,mutating=true,failurePolicy=fail,sideEffects=None,groups=run.terraform-operator.io,resources=terraforms,verbs=create;update,versions=v1alpha1,name=mterraform.terraform-operator.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-run-terraform-operator-io-v1alpha1-terraform,mutating=false,failurePolicy=fail,sideEffects=None,groups=run.terraform-operator.io,resources=terraforms,verbs=create;update,versions=v1alpha1,name=vterraform.terraform-operator.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the Terraform webhooks with the manager,
+// the conversion webhook is registered since v1alpha1 is the conversion hub,
+// the defaulting and validating webhooks use the given defaulter and validator
+func (r *Terraform) SetupWebhookWithManager(mgr ctrl.Manager, defaulter admission.CustomDefaulter, validator admission.CustomValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(defaulter).
+		WithValidator(validator).
+		Complete()
+}
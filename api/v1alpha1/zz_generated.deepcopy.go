@@ -22,9 +22,75 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Approval) DeepCopyInto(out *Approval) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Approval.
+func (in *Approval) DeepCopy() *Approval {
+	if in == nil {
+		return nil
+	}
+	out := new(Approval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CLIConfig) DeepCopyInto(out *CLIConfig) {
+	*out = *in
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = make([]CLICredential, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProviderInstallation != nil {
+		in, out := &in.ProviderInstallation, &out.ProviderInstallation
+		*out = make([]ProviderInstallationMethod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CLIConfig.
+func (in *CLIConfig) DeepCopy() *CLIConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CLIConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CLICredential) DeepCopyInto(out *CLICredential) {
+	*out = *in
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CLICredential.
+func (in *CLICredential) DeepCopy() *CLICredential {
+	if in == nil {
+		return nil
+	}
+	out := new(CLICredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DependsOn) DeepCopyInto(out *DependsOn) {
 	*out = *in
@@ -40,6 +106,148 @@ func (in *DependsOn) DeepCopy() *DependsOn {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftDetection) DeepCopyInto(out *DriftDetection) {
+	*out = *in
+	out.Interval = in.Interval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftDetection.
+func (in *DriftDetection) DeepCopy() *DriftDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftDetectionStatus) DeepCopyInto(out *DriftDetectionStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftDetectionStatus.
+func (in *DriftDetectionStatus) DeepCopy() *DriftDetectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftDetectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Engine) DeepCopyInto(out *Engine) {
+	*out = *in
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(EngineEncryption)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Engine.
+func (in *Engine) DeepCopy() *Engine {
+	if in == nil {
+		return nil
+	}
+	out := new(Engine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EngineEncryption) DeepCopyInto(out *EngineEncryption) {
+	*out = *in
+	in.ConfigFrom.DeepCopyInto(&out.ConfigFrom)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EngineEncryption.
+func (in *EngineEncryption) DeepCopy() *EngineEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(EngineEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtraFile) DeepCopyInto(out *ExtraFile) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(ExtraFileSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtraFile.
+func (in *ExtraFile) DeepCopy() *ExtraFile {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtraFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtraFileSource) DeepCopyInto(out *ExtraFileSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtraFileSource.
+func (in *ExtraFileSource) DeepCopy() *ExtraFileSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtraFileSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitHTTPSCredential) DeepCopyInto(out *GitHTTPSCredential) {
+	*out = *in
+	if in.UsernameFrom != nil {
+		in, out := &in.UsernameFrom, &out.UsernameFrom
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHTTPSCredential.
+func (in *GitHTTPSCredential) DeepCopy() *GitHTTPSCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHTTPSCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitSSHKey) DeepCopyInto(out *GitSSHKey) {
 	*out = *in
@@ -48,6 +256,23 @@ func (in *GitSSHKey) DeepCopyInto(out *GitSSHKey) {
 		*out = new(v1.VolumeSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]GitSSHKeyFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConfigFrom != nil {
+		in, out := &in.ConfigFrom, &out.ConfigFrom
+		*out = new(ExtraFileSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KnownHostsFrom != nil {
+		in, out := &in.KnownHostsFrom, &out.KnownHostsFrom
+		*out = new(ExtraFileSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSSHKey.
@@ -60,9 +285,39 @@ func (in *GitSSHKey) DeepCopy() *GitSSHKey {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitSSHKeyFile) DeepCopyInto(out *GitSSHKeyFile) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSSHKeyFile.
+func (in *GitSSHKeyFile) DeepCopy() *GitSSHKeyFile {
+	if in == nil {
+		return nil
+	}
+	out := new(GitSSHKeyFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Module) DeepCopyInto(out *Module) {
 	*out = *in
+	if in.SourceFrom != nil {
+		in, out := &in.SourceFrom, &out.SourceFrom
+		*out = new(ModuleSourceFrom)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]ModuleProvider, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Module.
@@ -75,6 +330,111 @@ func (in *Module) DeepCopy() *Module {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleCall) DeepCopyInto(out *ModuleCall) {
+	*out = *in
+	in.Module.DeepCopyInto(&out.Module)
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ModuleInput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleCall.
+func (in *ModuleCall) DeepCopy() *ModuleCall {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleCall)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleInput) DeepCopyInto(out *ModuleInput) {
+	*out = *in
+	if in.ModuleOutput != nil {
+		in, out := &in.ModuleOutput, &out.ModuleOutput
+		*out = new(ModuleOutputRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleInput.
+func (in *ModuleInput) DeepCopy() *ModuleInput {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleOutputRef) DeepCopyInto(out *ModuleOutputRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleOutputRef.
+func (in *ModuleOutputRef) DeepCopy() *ModuleOutputRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleOutputRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleProvider) DeepCopyInto(out *ModuleProvider) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleProvider.
+func (in *ModuleProvider) DeepCopy() *ModuleProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleSourceFrom) DeepCopyInto(out *ModuleSourceFrom) {
+	*out = *in
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = make([]ExtraFileSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleSourceFrom.
+func (in *ModuleSourceFrom) DeepCopy() *ModuleSourceFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleSourceFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Output) DeepCopyInto(out *Output) {
 	*out = *in
@@ -90,6 +450,21 @@ func (in *Output) DeepCopy() *Output {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputGrant) DeepCopyInto(out *OutputGrant) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputGrant.
+func (in *OutputGrant) DeepCopy() *OutputGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreviousRunStatus) DeepCopyInto(out *PreviousRunStatus) {
 	*out = *in
@@ -105,13 +480,143 @@ func (in *PreviousRunStatus) DeepCopy() *PreviousRunStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Provider) DeepCopyInto(out *Provider) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigFrom != nil {
+		in, out := &in.ConfigFrom, &out.ConfigFrom
+		*out = make([]ProviderArgumentSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Provider.
+func (in *Provider) DeepCopy() *Provider {
+	if in == nil {
+		return nil
+	}
+	out := new(Provider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderArgumentSource) DeepCopyInto(out *ProviderArgumentSource) {
+	*out = *in
+	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderArgumentSource.
+func (in *ProviderArgumentSource) DeepCopy() *ProviderArgumentSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderArgumentSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderInstallationMethod) DeepCopyInto(out *ProviderInstallationMethod) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderInstallationMethod.
+func (in *ProviderInstallationMethod) DeepCopy() *ProviderInstallationMethod {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderInstallationMethod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Runner) DeepCopyInto(out *Runner) {
+	*out = *in
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Runner.
+func (in *Runner) DeepCopy() *Runner {
+	if in == nil {
+		return nil
+	}
+	out := new(Runner)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RunnerServiceAccount) DeepCopyInto(out *RunnerServiceAccount) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunnerServiceAccount.
+func (in *RunnerServiceAccount) DeepCopy() *RunnerServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(RunnerServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountToken) DeepCopyInto(out *ServiceAccountToken) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountToken.
+func (in *ServiceAccountToken) DeepCopy() *ServiceAccountToken {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Terraform) DeepCopyInto(out *Terraform) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Terraform.
@@ -182,7 +687,35 @@ func (in *TerraformList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TerraformSpec) DeepCopyInto(out *TerraformSpec) {
 	*out = *in
-	out.Module = in.Module
+	if in.Engine != nil {
+		in, out := &in.Engine, &out.Engine
+		*out = new(Engine)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Module != nil {
+		in, out := &in.Module, &out.Module
+		*out = new(Module)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]ModuleCall, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TypedBackend != nil {
+		in, out := &in.TypedBackend, &out.TypedBackend
+		*out = new(TypedBackend)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TypedProviders != nil {
+		in, out := &in.TypedProviders, &out.TypedProviders
+		*out = make([]Provider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ProvidersCache != nil {
 		in, out := &in.ProvidersCache, &out.ProvidersCache
 		*out = new(v1.VolumeSource)
@@ -213,6 +746,13 @@ func (in *TerraformSpec) DeepCopyInto(out *TerraformSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExtraFiles != nil {
+		in, out := &in.ExtraFiles, &out.ExtraFiles
+		*out = make([]ExtraFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Outputs != nil {
 		in, out := &in.Outputs, &out.Outputs
 		*out = make([]*Output, len(*in))
@@ -224,11 +764,55 @@ func (in *TerraformSpec) DeepCopyInto(out *TerraformSpec) {
 			}
 		}
 	}
+	if in.OutputGrants != nil {
+		in, out := &in.OutputGrants, &out.OutputGrants
+		*out = make([]OutputGrant, len(*in))
+		copy(*out, *in)
+	}
 	if in.GitSSHKey != nil {
 		in, out := &in.GitSSHKey, &out.GitSSHKey
 		*out = new(GitSSHKey)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GitHTTPSCredentials != nil {
+		in, out := &in.GitHTTPSCredentials, &out.GitHTTPSCredentials
+		*out = make([]GitHTTPSCredential, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CLIConfig != nil {
+		in, out := &in.CLIConfig, &out.CLIConfig
+		*out = new(CLIConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Runner != nil {
+		in, out := &in.Runner, &out.Runner
+		*out = new(Runner)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(RunnerServiceAccount)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccountTokens != nil {
+		in, out := &in.ServiceAccountTokens, &out.ServiceAccountTokens
+		*out = make([]ServiceAccountToken, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DriftDetection != nil {
+		in, out := &in.DriftDetection, &out.DriftDetection
+		*out = new(DriftDetection)
+		**out = **in
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(Timeouts)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TerraformSpec.
@@ -244,6 +828,28 @@ func (in *TerraformSpec) DeepCopy() *TerraformSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TerraformStatus) DeepCopyInto(out *TerraformStatus) {
 	*out = *in
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(Approval)
+		**out = **in
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftDetectionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]PreviousRunStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TerraformStatus.
@@ -256,6 +862,75 @@ func (in *TerraformStatus) DeepCopy() *TerraformStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Timeouts) DeepCopyInto(out *Timeouts) {
+	*out = *in
+	if in.InitContainer != nil {
+		in, out := &in.InitContainer, &out.InitContainer
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Apply != nil {
+		in, out := &in.Apply, &out.Apply
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Pending != nil {
+		in, out := &in.Pending, &out.Pending
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Timeouts.
+func (in *Timeouts) DeepCopy() *Timeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(Timeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TypedBackend) DeepCopyInto(out *TypedBackend) {
+	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConfigFrom != nil {
+		in, out := &in.ConfigFrom, &out.ConfigFrom
+		*out = make([]v1.SecretKeySelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(v1.PersistentVolumeClaimVolumeSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TypedBackend.
+func (in *TypedBackend) DeepCopy() *TypedBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(TypedBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Variable) DeepCopyInto(out *Variable) {
 	*out = *in
@@ -269,6 +944,16 @@ func (in *Variable) DeepCopyInto(out *Variable) {
 		*out = new(TerraformDependencyRef)
 		**out = **in
 	}
+	if in.JSONValue != nil {
+		in, out := &in.JSONValue, &out.JSONValue
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Nullable != nil {
+		in, out := &in.Nullable, &out.Nullable
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Variable.
@@ -0,0 +1,13 @@
+package v1alpha2
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestV1alpha2(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "v1alpha2 Suite")
+}
@@ -0,0 +1,595 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+)
+
+// ConvertTo converts this Terraform to the Hub version (v1alpha1)
+func (src *Terraform) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Terraform)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.TerraformVersion = src.Spec.TerraformVersion
+	dst.Spec.Workspace = src.Spec.Workspace
+	dst.Spec.Destroy = src.Spec.Destroy
+	dst.Spec.DeletionPolicy = v1alpha1.DeletionPolicy(src.Spec.DeletionPolicy)
+	dst.Spec.DeleteCompletedJobs = src.Spec.DeleteCompletedJobs
+	dst.Spec.RetryLimit = src.Spec.RetryLimit
+	dst.Spec.ApprovalMode = v1alpha1.ApprovalMode(src.Spec.ApprovalMode)
+	dst.Spec.HistoryLimit = src.Spec.HistoryLimit
+	dst.Spec.RerunOnDependencyChange = src.Spec.RerunOnDependencyChange
+
+	if src.Spec.Backend != nil {
+		dst.Spec.Backend = src.Spec.Backend.Config
+
+		if typed := src.Spec.Backend.TypedBackend; typed.Type != "" || len(typed.Settings) > 0 || len(typed.ConfigFrom) > 0 || typed.PersistentVolumeClaim != nil {
+			dst.Spec.TypedBackend = &v1alpha1.TypedBackend{
+				Type:                  v1alpha1.BackendType(typed.Type),
+				Settings:              typed.Settings,
+				ConfigFrom:            typed.ConfigFrom,
+				PersistentVolumeClaim: typed.PersistentVolumeClaim,
+			}
+		}
+	}
+
+	if src.Spec.Providers != nil {
+		dst.Spec.ProvidersConfig = src.Spec.Providers.Config
+		dst.Spec.ProvidersCache = src.Spec.Providers.Cache
+
+		for _, p := range src.Spec.Providers.Typed {
+			provider := v1alpha1.Provider{Name: p.Name, Source: p.Source, Version: p.Version, Alias: p.Alias, Config: p.Config}
+
+			for _, a := range p.ConfigFrom {
+				provider.ConfigFrom = append(provider.ConfigFrom, v1alpha1.ProviderArgumentSource(a))
+			}
+
+			dst.Spec.TypedProviders = append(dst.Spec.TypedProviders, provider)
+		}
+	}
+
+	if src.Spec.Module != nil {
+		module := convertModuleToHub(*src.Spec.Module)
+		dst.Spec.Module = &module
+	}
+
+	for _, m := range src.Spec.Modules {
+		call := v1alpha1.ModuleCall{Name: m.Name, Module: convertModuleToHub(m.Module)}
+
+		for _, in := range m.Inputs {
+			input := v1alpha1.ModuleInput{Name: in.Name, Variable: in.Variable}
+
+			if in.ModuleOutput != nil {
+				input.ModuleOutput = &v1alpha1.ModuleOutputRef{Module: in.ModuleOutput.Module, Output: in.ModuleOutput.Output}
+			}
+
+			call.Inputs = append(call.Inputs, input)
+		}
+
+		dst.Spec.Modules = append(dst.Spec.Modules, call)
+	}
+
+	for _, d := range src.Spec.DependsOn {
+		dst.Spec.DependsOn = append(dst.Spec.DependsOn, &v1alpha1.DependsOn{Name: d.Name, Namespace: d.Namespace})
+	}
+
+	for _, v := range src.Spec.Variables {
+		variable := v1alpha1.Variable{
+			Key:                 v.Key,
+			Value:               v.Value,
+			ValueFrom:           v.ValueFrom,
+			EnvironmentVariable: v.EnvironmentVariable,
+			JSONValue:           v.JSONValue,
+			Type:                v.Type,
+			Sensitive:           v.Sensitive,
+			Nullable:            v.Nullable,
+		}
+
+		if v.DependencyRef != nil {
+			variable.DependencyRef = &v1alpha1.TerraformDependencyRef{
+				Name:      v.DependencyRef.Name,
+				Namespace: v.DependencyRef.Namespace,
+				Key:       v.DependencyRef.Key,
+			}
+		}
+
+		dst.Spec.Variables = append(dst.Spec.Variables, variable)
+	}
+
+	for _, f := range src.Spec.VariableFiles {
+		dst.Spec.VariableFiles = append(dst.Spec.VariableFiles, v1alpha1.VariableFile{Key: f.Key, ValueFrom: f.ValueFrom})
+	}
+
+	for _, f := range src.Spec.ExtraFiles {
+		file := v1alpha1.ExtraFile{Name: f.Name, Content: f.Content}
+
+		if f.ValueFrom != nil {
+			file.ValueFrom = &v1alpha1.ExtraFileSource{
+				ConfigMapKeyRef: f.ValueFrom.ConfigMapKeyRef,
+				SecretKeyRef:    f.ValueFrom.SecretKeyRef,
+			}
+		}
+
+		dst.Spec.ExtraFiles = append(dst.Spec.ExtraFiles, file)
+	}
+
+	for _, o := range src.Spec.Outputs {
+		dst.Spec.Outputs = append(dst.Spec.Outputs, &v1alpha1.Output{Key: o.Key, ModuleOutputName: o.ModuleOutputName, Module: o.Module})
+	}
+
+	for _, g := range src.Spec.OutputGrants {
+		dst.Spec.OutputGrants = append(dst.Spec.OutputGrants, v1alpha1.OutputGrant(g))
+	}
+
+	if key := src.Spec.GitSSHKey; key != nil {
+		dst.Spec.GitSSHKey = &v1alpha1.GitSSHKey{ValueFrom: key.ValueFrom}
+
+		for _, k := range key.Keys {
+			dst.Spec.GitSSHKey.Keys = append(dst.Spec.GitSSHKey.Keys, v1alpha1.GitSSHKeyFile{Name: k.Name, SecretKeyRef: k.SecretKeyRef})
+		}
+
+		if key.ConfigFrom != nil {
+			dst.Spec.GitSSHKey.ConfigFrom = &v1alpha1.ExtraFileSource{
+				ConfigMapKeyRef: key.ConfigFrom.ConfigMapKeyRef,
+				SecretKeyRef:    key.ConfigFrom.SecretKeyRef,
+			}
+		}
+
+		if key.KnownHostsFrom != nil {
+			dst.Spec.GitSSHKey.KnownHostsFrom = &v1alpha1.ExtraFileSource{
+				ConfigMapKeyRef: key.KnownHostsFrom.ConfigMapKeyRef,
+				SecretKeyRef:    key.KnownHostsFrom.SecretKeyRef,
+			}
+		}
+	}
+
+	for _, c := range src.Spec.GitHTTPSCredentials {
+		dst.Spec.GitHTTPSCredentials = append(dst.Spec.GitHTTPSCredentials, v1alpha1.GitHTTPSCredential{
+			URL:          c.URL,
+			Username:     c.Username,
+			UsernameFrom: c.UsernameFrom,
+			PasswordFrom: c.PasswordFrom,
+		})
+	}
+
+	if c := src.Spec.CLIConfig; c != nil {
+		dst.Spec.CLIConfig = &v1alpha1.CLIConfig{}
+
+		for _, cred := range c.Credentials {
+			dst.Spec.CLIConfig.Credentials = append(dst.Spec.CLIConfig.Credentials, v1alpha1.CLICredential{Host: cred.Host, TokenFrom: cred.TokenFrom})
+		}
+
+		for _, m := range c.ProviderInstallation {
+			dst.Spec.CLIConfig.ProviderInstallation = append(dst.Spec.CLIConfig.ProviderInstallation, v1alpha1.ProviderInstallationMethod(m))
+		}
+	}
+
+	if e := src.Spec.Engine; e != nil {
+		dst.Spec.Engine = &v1alpha1.Engine{
+			Type:              v1alpha1.EngineType(e.Type),
+			Version:           e.Version,
+			VersionConstraint: e.VersionConstraint,
+		}
+
+		if e.Encryption != nil {
+			dst.Spec.Engine.Encryption = &v1alpha1.EngineEncryption{ConfigFrom: e.Encryption.ConfigFrom}
+		}
+	}
+
+	if src.Spec.Runner != nil {
+		dst.Spec.Runner = &v1alpha1.Runner{PodTemplate: src.Spec.Runner.PodTemplate}
+	}
+
+	dst.Spec.ServiceAccountName = src.Spec.ServiceAccountName
+
+	if src.Spec.ServiceAccount != nil {
+		dst.Spec.ServiceAccount = &v1alpha1.RunnerServiceAccount{Annotations: src.Spec.ServiceAccount.Annotations}
+	}
+
+	for _, token := range src.Spec.ServiceAccountTokens {
+		dst.Spec.ServiceAccountTokens = append(dst.Spec.ServiceAccountTokens, v1alpha1.ServiceAccountToken(token))
+	}
+
+	dst.Spec.AllowSecretList = src.Spec.AllowSecretList
+
+	if src.Spec.DriftDetection != nil {
+		dst.Spec.DriftDetection = &v1alpha1.DriftDetection{
+			Interval:      src.Spec.DriftDetection.Interval,
+			AutoRemediate: src.Spec.DriftDetection.AutoRemediate,
+		}
+	}
+
+	if src.Spec.Timeouts != nil {
+		dst.Spec.Timeouts = &v1alpha1.Timeouts{
+			InitContainer: src.Spec.Timeouts.InitContainer,
+			Plan:          src.Spec.Timeouts.Plan,
+			Apply:         src.Spec.Timeouts.Apply,
+			Pending:       src.Spec.Timeouts.Pending,
+		}
+	}
+
+	// Status
+	dst.Status.RunID = src.Status.RunID
+	dst.Status.PreviousRunID = src.Status.PreviousRunID
+	dst.Status.OutputSecretName = src.Status.OutputSecretName
+	dst.Status.DependencyOutputsHash = src.Status.DependencyOutputsHash
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.RunStatus = v1alpha1.TerraformRunStatus(src.Status.RunStatus)
+	dst.Status.Message = src.Status.Message
+	dst.Status.StartedTime = convertTimeToHub(src.Status.StartedTime)
+	dst.Status.CompletionTime = convertTimeToHub(src.Status.CompletionTime)
+	dst.Status.Conditions = src.Status.Conditions
+
+	if src.Status.Approval != nil {
+		dst.Status.Approval = &v1alpha1.Approval{
+			RunID:        src.Status.Approval.RunID,
+			ApprovedBy:   src.Status.Approval.ApprovedBy,
+			ApprovalTime: convertTimeToHub(src.Status.Approval.ApprovalTime),
+		}
+	}
+
+	if src.Status.Drift != nil {
+		dst.Status.Drift = &v1alpha1.DriftDetectionStatus{
+			CheckID:       src.Status.Drift.CheckID,
+			Status:        v1alpha1.DriftStatus(src.Status.Drift.Status),
+			LastCheckTime: convertTimeToHub(src.Status.Drift.LastCheckTime),
+			Resources:     src.Status.Drift.Resources,
+		}
+	}
+
+	for _, h := range src.Status.History {
+		dst.Status.History = append(dst.Status.History, v1alpha1.PreviousRunStatus{
+			RunID:          h.RunID,
+			Status:         v1alpha1.TerraformRunStatus(h.Status),
+			Trigger:        v1alpha1.RunTrigger(h.Trigger),
+			Generation:     h.Generation,
+			StartedTime:    convertTimeToHub(h.StartedTime),
+			CompletionTime: convertTimeToHub(h.CompletionTime),
+			ConfigHash:     h.ConfigHash,
+		})
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha1) to this version
+func (dst *Terraform) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Terraform)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.TerraformVersion = src.Spec.TerraformVersion
+	dst.Spec.Workspace = src.Spec.Workspace
+	dst.Spec.Destroy = src.Spec.Destroy
+	dst.Spec.DeletionPolicy = DeletionPolicy(src.Spec.DeletionPolicy)
+	dst.Spec.DeleteCompletedJobs = src.Spec.DeleteCompletedJobs
+	dst.Spec.RetryLimit = src.Spec.RetryLimit
+	dst.Spec.ApprovalMode = ApprovalMode(src.Spec.ApprovalMode)
+	dst.Spec.HistoryLimit = src.Spec.HistoryLimit
+	dst.Spec.RerunOnDependencyChange = src.Spec.RerunOnDependencyChange
+
+	if src.Spec.Backend != "" || src.Spec.TypedBackend != nil {
+		dst.Spec.Backend = &Backend{Config: src.Spec.Backend}
+
+		if typed := src.Spec.TypedBackend; typed != nil {
+			dst.Spec.Backend.TypedBackend = TypedBackend{
+				Type:                  BackendType(typed.Type),
+				Settings:              typed.Settings,
+				ConfigFrom:            typed.ConfigFrom,
+				PersistentVolumeClaim: typed.PersistentVolumeClaim,
+			}
+		}
+	}
+
+	if src.Spec.ProvidersConfig != "" || src.Spec.ProvidersCache != nil || len(src.Spec.TypedProviders) > 0 {
+		dst.Spec.Providers = &Providers{Config: src.Spec.ProvidersConfig, Cache: src.Spec.ProvidersCache}
+
+		for _, p := range src.Spec.TypedProviders {
+			provider := Provider{Name: p.Name, Source: p.Source, Version: p.Version, Alias: p.Alias, Config: p.Config}
+
+			for _, a := range p.ConfigFrom {
+				provider.ConfigFrom = append(provider.ConfigFrom, ProviderArgumentSource(a))
+			}
+
+			dst.Spec.Providers.Typed = append(dst.Spec.Providers.Typed, provider)
+		}
+	}
+
+	if src.Spec.Module != nil {
+		module := convertModuleFromHub(*src.Spec.Module)
+		dst.Spec.Module = &module
+	}
+
+	for _, m := range src.Spec.Modules {
+		call := ModuleCall{Name: m.Name, Module: convertModuleFromHub(m.Module)}
+
+		for _, in := range m.Inputs {
+			input := ModuleInput{Name: in.Name, Variable: in.Variable}
+
+			if in.ModuleOutput != nil {
+				input.ModuleOutput = &ModuleOutputRef{Module: in.ModuleOutput.Module, Output: in.ModuleOutput.Output}
+			}
+
+			call.Inputs = append(call.Inputs, input)
+		}
+
+		dst.Spec.Modules = append(dst.Spec.Modules, call)
+	}
+
+	for _, d := range src.Spec.DependsOn {
+		if d != nil {
+			dst.Spec.DependsOn = append(dst.Spec.DependsOn, DependsOn{Name: d.Name, Namespace: d.Namespace})
+		}
+	}
+
+	for _, v := range src.Spec.Variables {
+		variable := Variable{
+			Key:                 v.Key,
+			Value:               v.Value,
+			ValueFrom:           v.ValueFrom,
+			EnvironmentVariable: v.EnvironmentVariable,
+			JSONValue:           v.JSONValue,
+			Type:                v.Type,
+			Sensitive:           v.Sensitive,
+			Nullable:            v.Nullable,
+		}
+
+		if v.DependencyRef != nil {
+			variable.DependencyRef = &TerraformDependencyRef{
+				Name:      v.DependencyRef.Name,
+				Namespace: v.DependencyRef.Namespace,
+				Key:       v.DependencyRef.Key,
+			}
+		}
+
+		dst.Spec.Variables = append(dst.Spec.Variables, variable)
+	}
+
+	for _, f := range src.Spec.VariableFiles {
+		dst.Spec.VariableFiles = append(dst.Spec.VariableFiles, VariableFile{Key: f.Key, ValueFrom: f.ValueFrom})
+	}
+
+	for _, f := range src.Spec.ExtraFiles {
+		file := ExtraFile{Name: f.Name, Content: f.Content}
+
+		if f.ValueFrom != nil {
+			file.ValueFrom = &ExtraFileSource{
+				ConfigMapKeyRef: f.ValueFrom.ConfigMapKeyRef,
+				SecretKeyRef:    f.ValueFrom.SecretKeyRef,
+			}
+		}
+
+		dst.Spec.ExtraFiles = append(dst.Spec.ExtraFiles, file)
+	}
+
+	for _, o := range src.Spec.Outputs {
+		if o != nil {
+			dst.Spec.Outputs = append(dst.Spec.Outputs, Output{Key: o.Key, ModuleOutputName: o.ModuleOutputName, Module: o.Module})
+		}
+	}
+
+	for _, g := range src.Spec.OutputGrants {
+		dst.Spec.OutputGrants = append(dst.Spec.OutputGrants, OutputGrant(g))
+	}
+
+	if key := src.Spec.GitSSHKey; key != nil {
+		dst.Spec.GitSSHKey = &GitSSHKey{ValueFrom: key.ValueFrom}
+
+		for _, k := range key.Keys {
+			dst.Spec.GitSSHKey.Keys = append(dst.Spec.GitSSHKey.Keys, GitSSHKeyFile{Name: k.Name, SecretKeyRef: k.SecretKeyRef})
+		}
+
+		if key.ConfigFrom != nil {
+			dst.Spec.GitSSHKey.ConfigFrom = &ExtraFileSource{
+				ConfigMapKeyRef: key.ConfigFrom.ConfigMapKeyRef,
+				SecretKeyRef:    key.ConfigFrom.SecretKeyRef,
+			}
+		}
+
+		if key.KnownHostsFrom != nil {
+			dst.Spec.GitSSHKey.KnownHostsFrom = &ExtraFileSource{
+				ConfigMapKeyRef: key.KnownHostsFrom.ConfigMapKeyRef,
+				SecretKeyRef:    key.KnownHostsFrom.SecretKeyRef,
+			}
+		}
+	}
+
+	for _, c := range src.Spec.GitHTTPSCredentials {
+		dst.Spec.GitHTTPSCredentials = append(dst.Spec.GitHTTPSCredentials, GitHTTPSCredential{
+			URL:          c.URL,
+			Username:     c.Username,
+			UsernameFrom: c.UsernameFrom,
+			PasswordFrom: c.PasswordFrom,
+		})
+	}
+
+	if c := src.Spec.CLIConfig; c != nil {
+		dst.Spec.CLIConfig = &CLIConfig{}
+
+		for _, cred := range c.Credentials {
+			dst.Spec.CLIConfig.Credentials = append(dst.Spec.CLIConfig.Credentials, CLICredential{Host: cred.Host, TokenFrom: cred.TokenFrom})
+		}
+
+		for _, m := range c.ProviderInstallation {
+			dst.Spec.CLIConfig.ProviderInstallation = append(dst.Spec.CLIConfig.ProviderInstallation, ProviderInstallationMethod(m))
+		}
+	}
+
+	if e := src.Spec.Engine; e != nil {
+		dst.Spec.Engine = &Engine{
+			Type:              EngineType(e.Type),
+			Version:           e.Version,
+			VersionConstraint: e.VersionConstraint,
+		}
+
+		if e.Encryption != nil {
+			dst.Spec.Engine.Encryption = &EngineEncryption{ConfigFrom: e.Encryption.ConfigFrom}
+		}
+	}
+
+	if src.Spec.Runner != nil {
+		dst.Spec.Runner = &Runner{PodTemplate: src.Spec.Runner.PodTemplate}
+	}
+
+	dst.Spec.ServiceAccountName = src.Spec.ServiceAccountName
+
+	if src.Spec.ServiceAccount != nil {
+		dst.Spec.ServiceAccount = &RunnerServiceAccount{Annotations: src.Spec.ServiceAccount.Annotations}
+	}
+
+	for _, token := range src.Spec.ServiceAccountTokens {
+		dst.Spec.ServiceAccountTokens = append(dst.Spec.ServiceAccountTokens, ServiceAccountToken(token))
+	}
+
+	dst.Spec.AllowSecretList = src.Spec.AllowSecretList
+
+	if src.Spec.DriftDetection != nil {
+		dst.Spec.DriftDetection = &DriftDetection{
+			Interval:      src.Spec.DriftDetection.Interval,
+			AutoRemediate: src.Spec.DriftDetection.AutoRemediate,
+		}
+	}
+
+	if src.Spec.Timeouts != nil {
+		dst.Spec.Timeouts = &Timeouts{
+			InitContainer: src.Spec.Timeouts.InitContainer,
+			Plan:          src.Spec.Timeouts.Plan,
+			Apply:         src.Spec.Timeouts.Apply,
+			Pending:       src.Spec.Timeouts.Pending,
+		}
+	}
+
+	// Status
+	dst.Status.RunID = src.Status.RunID
+	dst.Status.PreviousRunID = src.Status.PreviousRunID
+	dst.Status.OutputSecretName = src.Status.OutputSecretName
+	dst.Status.DependencyOutputsHash = src.Status.DependencyOutputsHash
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.RunStatus = TerraformRunStatus(src.Status.RunStatus)
+	dst.Status.Message = src.Status.Message
+	dst.Status.StartedTime = convertTimeFromHub(src.Status.StartedTime)
+	dst.Status.CompletionTime = convertTimeFromHub(src.Status.CompletionTime)
+	dst.Status.Conditions = src.Status.Conditions
+
+	if src.Status.Approval != nil {
+		dst.Status.Approval = &Approval{
+			RunID:        src.Status.Approval.RunID,
+			ApprovedBy:   src.Status.Approval.ApprovedBy,
+			ApprovalTime: convertTimeFromHub(src.Status.Approval.ApprovalTime),
+		}
+	}
+
+	if src.Status.Drift != nil {
+		dst.Status.Drift = &DriftDetectionStatus{
+			CheckID:       src.Status.Drift.CheckID,
+			Status:        DriftStatus(src.Status.Drift.Status),
+			LastCheckTime: convertTimeFromHub(src.Status.Drift.LastCheckTime),
+			Resources:     src.Status.Drift.Resources,
+		}
+	}
+
+	for _, h := range src.Status.History {
+		dst.Status.History = append(dst.Status.History, PreviousRunStatus{
+			RunID:          h.RunID,
+			Status:         TerraformRunStatus(h.Status),
+			Trigger:        RunTrigger(h.Trigger),
+			Generation:     h.Generation,
+			StartedTime:    convertTimeFromHub(h.StartedTime),
+			CompletionTime: convertTimeFromHub(h.CompletionTime),
+			ConfigHash:     h.ConfigHash,
+		})
+	}
+
+	return nil
+}
+
+// convertModuleToHub converts a module to a v1alpha1 module
+func convertModuleToHub(src Module) v1alpha1.Module {
+	dst := v1alpha1.Module{Source: src.Source, Version: src.Version}
+
+	for _, p := range src.Providers {
+		dst.Providers = append(dst.Providers, v1alpha1.ModuleProvider(p))
+	}
+
+	if src.SourceFrom != nil {
+		dst.SourceFrom = &v1alpha1.ModuleSourceFrom{ConfigMaps: src.SourceFrom.ConfigMaps, Secrets: src.SourceFrom.Secrets}
+
+		for _, a := range src.SourceFrom.Archive {
+			dst.SourceFrom.Archive = append(dst.SourceFrom.Archive, v1alpha1.ExtraFileSource{
+				ConfigMapKeyRef: a.ConfigMapKeyRef,
+				SecretKeyRef:    a.SecretKeyRef,
+			})
+		}
+	}
+
+	return dst
+}
+
+// convertModuleFromHub converts a v1alpha1 module to a module
+func convertModuleFromHub(src v1alpha1.Module) Module {
+	dst := Module{Source: src.Source, Version: src.Version}
+
+	for _, p := range src.Providers {
+		dst.Providers = append(dst.Providers, ModuleProvider(p))
+	}
+
+	if src.SourceFrom != nil {
+		dst.SourceFrom = &ModuleSourceFrom{ConfigMaps: src.SourceFrom.ConfigMaps, Secrets: src.SourceFrom.Secrets}
+
+		for _, a := range src.SourceFrom.Archive {
+			dst.SourceFrom.Archive = append(dst.SourceFrom.Archive, ExtraFileSource{
+				ConfigMapKeyRef: a.ConfigMapKeyRef,
+				SecretKeyRef:    a.SecretKeyRef,
+			})
+		}
+	}
+
+	return dst
+}
+
+// convertTimeFromHub converts a v1alpha1 timestamp (time.UnixDate) to a metav1.Time
+func convertTimeFromHub(value string) *metav1.Time {
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := time.Parse(time.UnixDate, value)
+	if err != nil {
+		return nil
+	}
+
+	t := metav1.NewTime(parsed)
+	return &t
+}
+
+// convertTimeToHub converts a metav1.Time to a v1alpha1 timestamp (time.UnixDate)
+func convertTimeToHub(value *metav1.Time) string {
+	if value == nil {
+		return ""
+	}
+
+	return value.UTC().Format(time.UnixDate)
+}
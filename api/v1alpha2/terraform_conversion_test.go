@@ -0,0 +1,180 @@
+package v1alpha2
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Terraform Conversion", func() {
+	startTime := time.Date(2023, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	hub := &v1alpha1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "terraform-workflow", Namespace: "default"},
+		Spec: v1alpha1.TerraformSpec{
+			TerraformVersion: "1.1.7",
+			Module:           &v1alpha1.Module{Source: "IbraheemAlSaady/test/module", Version: "0.0.1"},
+			Modules: []v1alpha1.ModuleCall{
+				{Name: "vpc", Module: v1alpha1.Module{Source: "terraform-aws-modules/vpc/aws"}, Inputs: []v1alpha1.ModuleInput{
+					{Name: "name", Variable: "name"},
+				}},
+				{Name: "eks", Module: v1alpha1.Module{Source: "terraform-aws-modules/eks/aws", Providers: []v1alpha1.ModuleProvider{
+					{Name: "aws", Provider: "aws.us_east_1"},
+				}}, Inputs: []v1alpha1.ModuleInput{
+					{Name: "vpc_id", ModuleOutput: &v1alpha1.ModuleOutputRef{Module: "vpc", Output: "vpc_id"}},
+				}},
+				{Name: "glue", Module: v1alpha1.Module{SourceFrom: &v1alpha1.ModuleSourceFrom{
+					ConfigMaps: []corev1.LocalObjectReference{{Name: "glue-module"}},
+					Archive: []v1alpha1.ExtraFileSource{
+						{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "glue-templates"}, Key: "templates.tar.gz"}},
+					},
+				}}},
+			},
+			Backend:                 `backend "local" {}`,
+			ProvidersConfig:         `provider "aws" {}`,
+			ProvidersCache:          &corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			DependsOn:               []*v1alpha1.DependsOn{{Name: "first"}},
+			RerunOnDependencyChange: true,
+			Variables: []v1alpha1.Variable{
+				{Key: "length", Value: "4", Type: "number"},
+				{Key: "tags", JSONValue: &apiextensionsv1.JSON{Raw: []byte(`{"team":"a"}`)}, Sensitive: true},
+				{Key: "name", DependencyRef: &v1alpha1.TerraformDependencyRef{Name: "first", Key: "result"}},
+				{Key: "vpc_id", DependencyRef: &v1alpha1.TerraformDependencyRef{Name: "network", Namespace: "platform", Key: "vpc_id"}},
+			},
+			ExtraFiles: []v1alpha1.ExtraFile{
+				{Name: "locals.tf", Content: `locals { name = "test" }`},
+				{Name: "moved.tf", ValueFrom: &v1alpha1.ExtraFileSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "refactoring"}, Key: "moved.tf"},
+				}},
+			},
+			Outputs:      []*v1alpha1.Output{{Key: "result", ModuleOutputName: "result"}, {Key: "cluster", ModuleOutputName: "cluster_name", Module: "eks"}},
+			OutputGrants: []v1alpha1.OutputGrant{{Namespace: "team-a"}},
+			ApprovalMode: v1alpha1.ApprovalManual,
+			GitSSHKey: &v1alpha1.GitSSHKey{
+				Keys: []v1alpha1.GitSSHKeyFile{
+					{Name: "id_ed25519", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "github-key"}, Key: "key"}},
+				},
+				ConfigFrom: &v1alpha1.ExtraFileSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ssh"}, Key: "config"},
+				},
+				KnownHostsFrom: &v1alpha1.ExtraFileSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ssh"}, Key: "known_hosts"},
+				},
+			},
+			GitHTTPSCredentials: []v1alpha1.GitHTTPSCredential{
+				{URL: "https://gitlab.com", Username: "oauth2", PasswordFrom: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "gitlab"}, Key: "token"}},
+			},
+			CLIConfig: &v1alpha1.CLIConfig{
+				Credentials: []v1alpha1.CLICredential{
+					{Host: "registry.example.com", TokenFrom: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "registry"}, Key: "token"}},
+				},
+				ProviderInstallation: []v1alpha1.ProviderInstallationMethod{
+					{NetworkMirror: "https://mirror.example.com/providers/", Include: []string{"registry.terraform.io/*/*"}},
+					{Direct: true, Exclude: []string{"registry.terraform.io/*/*"}},
+				},
+			},
+			Runner: &v1alpha1.Runner{
+				PodTemplate: &apiextensionsv1.JSON{Raw: []byte(`{"spec":{"nodeSelector":{"pool":"terraform"}}}`)},
+			},
+			ServiceAccount: &v1alpha1.RunnerServiceAccount{
+				Annotations: map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/network"},
+			},
+			ServiceAccountTokens: []v1alpha1.ServiceAccountToken{{Path: "aws", Audience: "sts.amazonaws.com"}},
+			AllowSecretList:      true,
+			Timeouts: &v1alpha1.Timeouts{
+				InitContainer: &metav1.Duration{Duration: 5 * time.Minute},
+				Plan:          &metav1.Duration{Duration: 30 * time.Minute},
+				Pending:       &metav1.Duration{Duration: 10 * time.Minute},
+			},
+			Engine: &v1alpha1.Engine{
+				Type: v1alpha1.EngineOpenTofu, Version: "1.8.2", VersionConstraint: ">= 1.8",
+				Encryption: &v1alpha1.EngineEncryption{
+					ConfigFrom: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tofu"}, Key: "encryption"},
+				},
+			},
+			TypedProviders: []v1alpha1.Provider{
+				{
+					Name: "aws", Source: "hashicorp/aws", Version: "~> 5.0", Alias: "us_east_1",
+					Config: &apiextensionsv1.JSON{Raw: []byte(`{"region":"us-east-1"}`)},
+					ConfigFrom: []v1alpha1.ProviderArgumentSource{
+						{Name: "access_key", SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "aws"}, Key: "access_key"}},
+					},
+				},
+			},
+		},
+		Status: v1alpha1.TerraformStatus{
+			RunID:                 "abc123",
+			RunStatus:             v1alpha1.RunCompleted,
+			DependencyOutputsHash: "3d4e5f",
+			StartedTime:           startTime.Format(time.UnixDate),
+			CompletionTime:        startTime.Add(time.Minute).Format(time.UnixDate),
+			Approval:              &v1alpha1.Approval{RunID: "abc123", ApprovedBy: "jane.doe", ApprovalTime: startTime.Format(time.UnixDate)},
+			History: []v1alpha1.PreviousRunStatus{
+				{
+					RunID:          "abc123",
+					Status:         v1alpha1.RunCompleted,
+					Trigger:        v1alpha1.TriggerCreated,
+					Generation:     1,
+					StartedTime:    startTime.Format(time.UnixDate),
+					CompletionTime: startTime.Add(time.Minute).Format(time.UnixDate),
+					ConfigHash:     "0a1b2c",
+				},
+			},
+		},
+	}
+
+	Context("Converting from v1alpha1", func() {
+		It("should convert the timestamps and the structured sections", func() {
+			dst := &Terraform{}
+
+			Expect(dst.ConvertFrom(hub.DeepCopy())).To(Succeed())
+
+			Expect(dst.Spec.Backend).To(Equal(&Backend{Config: `backend "local" {}`}))
+			Expect(dst.Spec.Providers.Config).To(Equal(`provider "aws" {}`))
+			Expect(dst.Spec.Providers.Cache).ToNot(BeNil())
+			Expect(dst.Spec.Providers.Typed).To(HaveLen(1))
+			Expect(dst.Spec.DependsOn).To(Equal([]DependsOn{{Name: "first"}}))
+			Expect(dst.Spec.Outputs).To(Equal([]Output{{Key: "result", ModuleOutputName: "result"}, {Key: "cluster", ModuleOutputName: "cluster_name", Module: "eks"}}))
+			Expect(dst.Status.StartedTime.Time.Equal(startTime)).To(BeTrue())
+			Expect(dst.Status.CompletionTime.Time.Equal(startTime.Add(time.Minute))).To(BeTrue())
+			Expect(dst.Status.Approval.ApprovalTime.Time.Equal(startTime)).To(BeTrue())
+			Expect(dst.Status.History[0].CompletionTime.Time.Equal(startTime.Add(time.Minute))).To(BeTrue())
+		})
+	})
+
+	Context("Converting back to v1alpha1", func() {
+		It("should not lose any information", func() {
+			spoke := &Terraform{}
+			Expect(spoke.ConvertFrom(hub.DeepCopy())).To(Succeed())
+
+			dst := &v1alpha1.Terraform{}
+			Expect(spoke.ConvertTo(dst)).To(Succeed())
+
+			Expect(dst).To(Equal(hub))
+		})
+
+		It("should keep the typed backend", func() {
+			src := hub.DeepCopy()
+			src.Spec.Backend = ""
+			src.Spec.TypedBackend = &v1alpha1.TypedBackend{
+				Type:       v1alpha1.BackendS3,
+				Settings:   map[string]string{"bucket": "state"},
+				ConfigFrom: []corev1.SecretKeySelector{{LocalObjectReference: corev1.LocalObjectReference{Name: "aws"}, Key: "backend"}},
+			}
+
+			spoke := &Terraform{}
+			Expect(spoke.ConvertFrom(src.DeepCopy())).To(Succeed())
+			Expect(spoke.Spec.Backend.Type).To(Equal(BackendS3))
+
+			dst := &v1alpha1.Terraform{}
+			Expect(spoke.ConvertTo(dst)).To(Succeed())
+
+			Expect(dst).To(Equal(src))
+		})
+	})
+})
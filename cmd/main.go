@@ -37,8 +37,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"github.com/rinswind/terraform-operator/api/v1alpha2"
 	"github.com/rinswind/terraform-operator/internal/controllers"
 	"github.com/rinswind/terraform-operator/internal/metrics"
+	"github.com/rinswind/terraform-operator/internal/terraform"
 	"github.com/rinswind/terraform-operator/internal/utils"
 	//+kubebuilder:scaffold:imports
 )
@@ -48,12 +50,14 @@ var (
 	setupLog          = ctrl.Log.WithName("setup")
 	requeueDependency time.Duration
 	requeueJobWatch   time.Duration
+	requeueDependents time.Duration
 )
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(v1alpha2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -65,6 +69,7 @@ func main() {
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.DurationVar(&requeueJobWatch, "requeue-job-watch", 10*time.Second, "The interval at which job status is reevaluated after a workflow is submitted.")
 	flag.DurationVar(&requeueDependency, "requeue-dependency", 20*time.Second, "The interval at which dependencies are reevaluated.")
+	flag.DurationVar(&requeueDependents, "requeue-dependents", 20*time.Second, "The interval at which the dependents of a run being destroyed are reevaluated.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -94,6 +99,9 @@ func main() {
 
 	setupLog.Info(fmt.Sprintf("requeue dependency interval: %s", requeueDependency))
 	setupLog.Info(fmt.Sprintf("requeue job watch interval: %s", requeueJobWatch))
+	setupLog.Info(fmt.Sprintf("requeue dependents interval: %s", requeueDependents))
+
+	webhooksEnabled := os.Getenv("ENABLE_WEBHOOKS") != "false"
 
 	if err = (&controllers.TerraformReconciler{
 		Client:          mgr.GetClient(),
@@ -104,10 +112,22 @@ func main() {
 	}).SetupWithManager(mgr, controllers.TerraformReconcilerOptions{
 		RequeueDependencyInterval: requeueDependency,
 		RequeueJobWatchInterval:   requeueJobWatch,
+		RequeueDependentsInterval: requeueDependents,
+		WebhooksEnabled:           webhooksEnabled,
 	}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Terraform")
 		os.Exit(1)
 	}
+
+	if webhooksEnabled {
+		defaulter := &terraform.TerraformDefaulter{}
+		validator := &terraform.TerraformValidator{Client: mgr.GetClient()}
+
+		if err = (&v1alpha1.Terraform{}).SetupWebhookWithManager(mgr, defaulter, validator); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Terraform")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err = mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -25,10 +25,16 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -48,12 +54,17 @@ type TerraformReconciler struct {
 	Log               logr.Logger
 	requeueDependency time.Duration
 	requeueJobWatch   time.Duration
+	requeueDependents time.Duration
+	webhooksEnabled   bool
 }
 
 // TerraformReconcilerOptions holds additional options
 type TerraformReconcilerOptions struct {
 	RequeueDependencyInterval time.Duration
 	RequeueJobWatchInterval   time.Duration
+	RequeueDependentsInterval time.Duration
+	// WebhooksEnabled indicates that the mutating webhook records the approvers, approvals are ignored otherwise
+	WebhooksEnabled bool
 }
 
 //+kubebuilder:rbac:groups=run.terraform-operator.io,resources=terraforms,verbs=get;list;watch;create;update;patch;delete
@@ -103,8 +114,31 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return r.handleRunDelete(ctx, t)
 	}
 
+	// Record the defaults of new objects in case they weren't set by the defaulting webhook
+	if t.IsSubmitted() && t.SetDefaults() {
+		if err := r.Update(ctx, t.Terraform); err != nil {
+			r.Log.Error(err, "unable to set the defaults")
+			return ctrl.Result{}, err
+		}
+
+		r.Recorder.Event(t, corev1.EventTypeNormal, "Defaulted", "Object spec defaults are set")
+
+		return ctrl.Result{}, nil
+	}
+
+	// the mirrored outputs of a dependency are removed as soon as the dependency is dropped or revokes its grant
+	if err := t.DeleteStaleDependencyOutputs(ctx, r.Client); err != nil {
+		r.Log.Error(err, "unable to delete the stale outputs of the dependencies")
+		return ctrl.Result{}, err
+	}
+
 	if t.IsSubmitted() || t.IsWaiting() {
-		result, err := r.handleRunCreate(ctx, t)
+		trigger := v1alpha1.TriggerCreated
+		if !t.IsSubmitted() {
+			trigger = v1alpha1.TriggerUpdated
+		}
+
+		result, err := r.handleRunCreate(ctx, t, trigger)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -150,6 +184,29 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
+	if t.IsAwaitingApproval() {
+		return r.handleRunApproval(ctx, t)
+	}
+
+	if t.IsCompleted() && t.RerunsOnDependencyChange() {
+		changed, err := t.HaveDependencyOutputsChanged(ctx, r.Client)
+		if err != nil {
+			r.Log.Error(err, "unable to check the outputs of the dependencies for changes")
+			return ctrl.Result{}, err
+		}
+
+		if changed {
+			r.Recorder.Event(t, "Normal", "DependencyChanged", "Creating a new run job, the outputs of the dependencies changed")
+			r.MetricsRecorder.RecordTotal(t.Name, t.Namespace)
+
+			return r.handleRunCreate(ctx, t, v1alpha1.TriggerDependencyChanged)
+		}
+	}
+
+	if t.IsCompleted() && t.HasDriftDetection() {
+		return r.handleRunDriftDetection(ctx, t)
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -157,24 +214,97 @@ func (r *TerraformReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 // the owned resources (Jobs, ConfigMaps, Secrets) that will trigger reconcile
 // events when they change state. This establishes the controller's watch
 // relationships and sets reconciler options for requeue intervals.
+// The Terraform objects are indexed by their dependencies, so the dependents
+// of a Terraform object are reconciled when its run status or spec changes.
 func (r *TerraformReconciler) SetupWithManager(mgr ctrl.Manager, opts TerraformReconcilerOptions) error {
 	r.requeueDependency = opts.RequeueDependencyInterval
 	r.requeueJobWatch = opts.RequeueJobWatchInterval
+	r.requeueDependents = opts.RequeueDependentsInterval
+	r.webhooksEnabled = opts.WebhooksEnabled
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.Terraform{},
+		terraform.DependsOnIndexKey, terraform.IndexDependsOn); err != nil {
+		return err
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Terraform{}).
 		Owns(&batchv1.Job{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Secret{}).
+		Watches(&v1alpha1.Terraform{},
+			handler.EnqueueRequestsFromMapFunc(r.findDependents),
+			builder.WithPredicates(dependencyChangedPredicate())).
 		Complete(r)
 }
 
+// findDependents maps a Terraform object to the reconcile requests of the Terraform objects that depend on it
+func (r *TerraformReconciler) findDependents(ctx context.Context, obj client.Object) []reconcile.Request {
+	run, ok := obj.(*v1alpha1.Terraform)
+	if !ok {
+		return nil
+	}
+
+	t := &terraform.TerraformManipulator{Terraform: run}
+
+	dependents, err := t.GetDependents(ctx, r.Client)
+	if err != nil {
+		r.Log.Error(err, "unable to get the dependents", "name", run.Name, "namespace", run.Namespace)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, d := range dependents {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: d.Namespace, Name: d.Name},
+		})
+	}
+
+	return requests
+}
+
+// dependencyChangedPredicate filters the updates of a Terraform object that can unblock or
+// change the runs of its dependents, i.e. a new run status or a new spec (e.g. output grants)
+func dependencyChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldRun, ok := e.ObjectOld.(*v1alpha1.Terraform)
+			if !ok {
+				return false
+			}
+
+			newRun, ok := e.ObjectNew.(*v1alpha1.Terraform)
+			if !ok {
+				return false
+			}
+
+			return oldRun.Status.RunStatus != newRun.Status.RunStatus || oldRun.Generation != newRun.Generation
+		},
+	}
+}
+
 // handleRunCreate handles the creation of a new Terraform run. It checks dependencies,
-// waits for them to complete if necessary, sets variables from dependencies,
+// waits for them to complete if necessary, sets variables from dependencies (mirroring
+// the outputs of the dependencies in other namespaces),
 // creates the Terraform run job, cleans up old resources, and updates the run status.
-func (r *TerraformReconciler) handleRunCreate(ctx context.Context, t *terraform.TerraformManipulator) (ctrl.Result, error) {
+// The trigger is the reason the run is created, as recorded in the run history.
+func (r *TerraformReconciler) handleRunCreate(ctx context.Context, t *terraform.TerraformManipulator, trigger v1alpha1.RunTrigger) (ctrl.Result, error) {
+	// the spec is only validated on admission when the webhooks are enabled
+	if err := t.Validate().ToAggregate(); err != nil {
+		t.RejectTerraformRun(trigger)
+
+		msg := fmt.Sprintf("Run(%s) has an invalid spec: %s", t.Status.RunID, err)
+		r.Recorder.Event(t, "Warning", "InvalidSpec", msg)
+
+		// Always bail out after updating the status
+		err := r.updateRunStatus(ctx, t, v1alpha1.RunFailed, msg)
+		return ctrl.Result{}, err
+	}
+
 	dependencies, err := t.CheckDependencies(ctx, r.Client)
 
+	// the run is reconciled again once a dependency is created, updated or its run status changes,
+	// and periodically in case such a change was missed (e.g. while the operator was down)
 	if err != nil {
 		if t.IsWaiting() {
 			return ctrl.Result{RequeueAfter: r.requeueDependency}, nil
@@ -183,18 +313,30 @@ func (r *TerraformReconciler) handleRunCreate(ctx context.Context, t *terraform.
 		r.Recorder.Event(t, "Normal", "Waiting", "Dependencies are not yet completed")
 
 		// Always bail out after updating the status
-		err := r.updateRunStatus(ctx, t, v1alpha1.RunWaitingForDependency)
-		return ctrl.Result{RequeueAfter: r.requeueDependency}, err
+		if err := r.updateRunStatus(ctx, t, v1alpha1.RunWaitingForDependency, err.Error()); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: r.requeueDependency}, nil
+	}
+
+	if err := t.MirrorDependencyOutputs(ctx, r.Client, dependencies); err != nil {
+		r.Log.Error(err, "failed to mirror the outputs of the dependencies")
+		return ctrl.Result{}, err
+	}
+
+	if err := t.SetDependencyOutputsHash(ctx, r.Client, dependencies); err != nil {
+		r.Log.Error(err, "failed to hash the outputs of the dependencies")
+		return ctrl.Result{}, err
 	}
 
 	t.SetVariablesFromDependencies(dependencies)
 
-	_, err = t.CreateTerraformRun(ctx, r.Client)
+	_, err = t.CreateTerraformRun(ctx, r.Client, trigger)
 	if err != nil {
 		r.Log.Error(err, "failed create a terraform run")
 
 		// Always bail out after updating the status
-		if err := r.updateRunStatus(ctx, t, v1alpha1.RunFailed); err != nil {
+		if err := r.updateRunStatus(ctx, t, v1alpha1.RunFailed, fmt.Sprintf("Run(%s) could not be created: %s", t.Status.RunID, err)); err != nil {
 			r.Log.Error(err, "failed to update status", "name", t.ObjectMeta.Name, "namespace", t.ObjectMeta.Namespace, "status", v1alpha1.RunFailed)
 		}
 		return ctrl.Result{}, err
@@ -207,7 +349,7 @@ func (r *TerraformReconciler) handleRunCreate(ctx context.Context, t *terraform.
 	}
 
 	// Always bail out after updating the status
-	err = r.updateRunStatus(ctx, t, v1alpha1.RunStarted)
+	err = r.updateRunStatus(ctx, t, v1alpha1.RunStarted, fmt.Sprintf("Run(%s) %s job started", t.Status.RunID, t.GetCurrentJobType()))
 	return ctrl.Result{}, err
 }
 
@@ -217,15 +359,23 @@ func (r *TerraformReconciler) handleRunCreate(ctx context.Context, t *terraform.
 func (r *TerraformReconciler) handleRunUpdate(ctx context.Context, t *terraform.TerraformManipulator) (ctrl.Result, error) {
 	r.Recorder.Event(t, "Normal", "Updated", "Creating a new run job")
 
-	return r.handleRunCreate(ctx, t)
+	return r.handleRunCreate(ctx, t, v1alpha1.TriggerUpdated)
 }
 
 // handleRunDelete handles the deletion of a Terraform resource by cleaning up finalizers.
 // This method is called when a Terraform resource is marked for deletion, allowing for
-// graceful cleanup of resources and metrics recording before removal.
+// graceful cleanup of resources and metrics recording before removal. With the Destroy
+// deletion policy the finalizer is only removed once the infrastructure was destroyed.
 func (r *TerraformReconciler) handleRunDelete(ctx context.Context, t *terraform.TerraformManipulator) (ctrl.Result, error) {
 	r.Log.Info("terraform run is being deleted", "name", t.Name)
 
+	if t.DestroysOnDelete() {
+		result, destroyed, err := r.handleRunDestroy(ctx, t)
+		if err != nil || !destroyed {
+			return result, err
+		}
+	}
+
 	r.MetricsRecorder.RecordStatus(t.Name, t.Namespace, v1alpha1.RunDeleted)
 	controllerutil.RemoveFinalizer(t, v1alpha1.TerraformFinalizer)
 
@@ -236,11 +386,289 @@ func (r *TerraformReconciler) handleRunDelete(ctx context.Context, t *terraform.
 	return ctrl.Result{}, nil
 }
 
+// handleRunDestroy destroys the infrastructure of a Terraform resource that is being deleted.
+// It waits for the runs that depend on it to be deleted first, then creates a destroy job for
+// the current run and waits for it to complete. It returns true once the infrastructure is destroyed.
+func (r *TerraformReconciler) handleRunDestroy(ctx context.Context, t *terraform.TerraformManipulator) (ctrl.Result, bool, error) {
+	dependents, err := t.GetDependents(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+
+	if len(dependents) > 0 {
+		r.Log.Info("waiting for dependent runs to be deleted", "name", t.Name, "dependents", len(dependents))
+		return ctrl.Result{RequeueAfter: r.requeueDependents}, false, nil
+	}
+
+	job, err := t.GetJobForRun(ctx, r.Client, t.Status.RunID, terraform.DestroyJob)
+	if errors.IsNotFound(err) {
+		// the spec is only validated on admission when the webhooks are enabled, it can still be fixed during the deletion
+		if err := t.Validate().ToAggregate(); err != nil {
+			msg := fmt.Sprintf("Run(%s) destroy failed, the spec is invalid: %s", t.Status.RunID, err)
+			if t.HasErrored() && t.Status.Message == msg {
+				return ctrl.Result{}, false, nil
+			}
+
+			r.Recorder.Event(t, "Warning", "DestroyFailed", msg)
+
+			// Always bail out after updating the status
+			return ctrl.Result{}, false, r.updateRunStatus(ctx, t, v1alpha1.RunFailed, msg)
+		}
+
+		stopped, err := t.StopRunJobs(ctx, r.Client)
+		if err != nil {
+			return ctrl.Result{}, false, err
+		}
+
+		if !stopped {
+			r.Log.Info("waiting for the jobs of the run to stop before destroying it", "name", t.Name, "runId", t.Status.RunID)
+			return ctrl.Result{RequeueAfter: r.requeueJobWatch}, false, nil
+		}
+
+		dependencies, err := t.GetDependencies(ctx, r.Client)
+		if err != nil {
+			r.Log.Error(err, "unable to get the dependencies of the run to destroy")
+			return ctrl.Result{RequeueAfter: r.requeueDependency}, false, nil
+		}
+
+		if err := t.MirrorDependencyOutputs(ctx, r.Client, dependencies); err != nil {
+			r.Log.Error(err, "unable to mirror the outputs of the dependencies of the run to destroy")
+			return ctrl.Result{RequeueAfter: r.requeueDependency}, false, nil
+		}
+
+		t.SetVariablesFromDependencies(dependencies)
+
+		if _, err := t.CreateDestroyJob(ctx, r.Client); err != nil {
+			r.Log.Error(err, "failed to create the terraform destroy job")
+			return ctrl.Result{}, false, err
+		}
+
+		msg := fmt.Sprintf("Run(%s) destroy job created", t.Status.RunID)
+		r.Recorder.Event(t, "Normal", "Destroying", msg)
+
+		// Always bail out after updating the status
+		err = r.updateRunStatus(ctx, t, v1alpha1.RunDeleting, msg)
+		return ctrl.Result{}, false, err
+	}
+
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+
+	// job is successful
+	if job.Status.Succeeded > 0 {
+		r.Recorder.Event(t, "Normal", "Destroyed", fmt.Sprintf("Run(%s) infrastructure destroyed", t.Status.RunID))
+		return ctrl.Result{}, true, nil
+	}
+
+	timeout, err := t.CheckJobTimeouts(ctx, r.Client, job, terraform.DestroyJob)
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+
+	// job failed or timed out, keep the finalizer until the job is deleted to retry or the deletion policy is changed
+	if job.Status.Failed > 0 || timeout != "" {
+		if t.HasErrored() {
+			return ctrl.Result{}, false, nil
+		}
+
+		msg := fmt.Sprintf("Run(%s) destroy failed, delete job '%s' to retry or set the deletion policy to %s", t.Status.RunID, job.Name, v1alpha1.DeletionPolicyOrphan)
+		if timeout != "" {
+			msg = fmt.Sprintf("Run(%s) destroy timed out, %s, delete job '%s' to retry or set the deletion policy to %s", t.Status.RunID, timeout, job.Name, v1alpha1.DeletionPolicyOrphan)
+		}
+		r.Recorder.Event(t, "Warning", "DestroyFailed", msg)
+
+		// Always bail out after updating the status
+		err = r.updateRunStatus(ctx, t, v1alpha1.RunFailed, msg)
+		return ctrl.Result{}, false, err
+	}
+
+	// job is still running
+	return ctrl.Result{RequeueAfter: r.requeueJobWatch}, false, nil
+}
+
+// handleRunApproval handles a planned Terraform run that waits for an approval. The run is
+// approved by annotating the Terraform resource with the ID of the planned run, the mutating
+// webhook records the authenticated user who did it as the approver, at which point the job
+// that applies the spec of this run is created.
+func (r *TerraformReconciler) handleRunApproval(ctx context.Context, t *terraform.TerraformManipulator) (ctrl.Result, error) {
+	runID, approver := t.GetApproval()
+
+	if runID == "" {
+		return ctrl.Result{}, nil
+	}
+
+	// the ignored approvals are removed so they are only reported once
+	if runID != t.Status.RunID {
+		r.Recorder.Event(t, "Warning", "ApprovalIgnored",
+			fmt.Sprintf("Approval for Run(%s) ignored and removed, Run(%s) is awaiting approval", runID, t.Status.RunID))
+		return ctrl.Result{}, t.ClearApproval(ctx, r.Client)
+	}
+
+	// without the webhooks the approver is whatever the annotation says
+	if !r.webhooksEnabled {
+		r.Recorder.Event(t, "Warning", "ApprovalIgnored",
+			fmt.Sprintf("Approval for Run(%s) ignored and removed, the approver can only be recorded with the webhooks enabled", runID))
+		return ctrl.Result{}, t.ClearApproval(ctx, r.Client)
+	}
+
+	if approver == "" {
+		r.Recorder.Event(t, "Warning", "ApprovalIgnored",
+			fmt.Sprintf("Approval for Run(%s) ignored and removed, the '%s' annotation is missing", runID, v1alpha1.ApproverAnnotation))
+		return ctrl.Result{}, t.ClearApproval(ctx, r.Client)
+	}
+
+	r.Log.Info("terraform run approved", "runId", runID, "approver", approver)
+
+	// the apply job was created by a previous attempt whose status update failed
+	if _, err := t.ApproveTerraformRun(ctx, r.Client, approver); err != nil && !errors.IsAlreadyExists(err) {
+		r.Log.Error(err, "failed create the terraform apply job")
+
+		// Always bail out after updating the status
+		if err := r.updateRunStatus(ctx, t, v1alpha1.RunFailed, fmt.Sprintf("Run(%s) apply job could not be created: %s", runID, err)); err != nil {
+			r.Log.Error(err, "failed to update status", "name", t.ObjectMeta.Name, "namespace", t.ObjectMeta.Namespace, "status", v1alpha1.RunFailed)
+		}
+		return ctrl.Result{}, err
+	}
+
+	msg := fmt.Sprintf("Run(%s) approved by %s", runID, approver)
+	r.Recorder.Event(t, "Normal", "Approved", msg)
+
+	// Always bail out after updating the status
+	err := r.updateRunStatus(ctx, t, v1alpha1.RunStarted, msg)
+	return ctrl.Result{}, err
+}
+
+// handleRunDriftDetection periodically checks a completed Terraform run for drift. Once the drift
+// detection interval elapsed it creates a job that runs a refresh-only plan, then waits for the job
+// to report the drifted resources and, if enabled, submits a new run to remediate the drift.
+func (r *TerraformReconciler) handleRunDriftDetection(ctx context.Context, t *terraform.TerraformManipulator) (ctrl.Result, error) {
+	if t.IsCheckingDrift() {
+		job, err := t.GetJobForRun(ctx, r.Client, t.Status.RunID, terraform.DriftJob)
+		if err == nil {
+			return r.handleDriftJobWatch(ctx, t, job)
+		}
+
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		r.Log.Info("drift check job not found, starting a new drift check")
+	} else if next := time.Until(t.GetNextDriftCheckTime()); next > 0 {
+		return ctrl.Result{RequeueAfter: next}, nil
+	}
+
+	// the check is retried after the interval, the runner image won't support it any sooner
+	if err := t.CheckDriftDetectionSupport(); err != nil {
+		r.Recorder.Event(t, "Warning", "DriftCheckFailed", fmt.Sprintf("Run(%s) drift check could not be started: %s", t.Status.RunID, err))
+
+		t.SetDriftCheckResult(v1alpha1.DriftCheckFailed, nil)
+
+		err := r.Status().Update(ctx, t.Terraform)
+		return ctrl.Result{RequeueAfter: t.Spec.DriftDetection.Interval.Duration}, err
+	}
+
+	if _, err := t.StartDriftCheck(ctx, r.Client); err != nil {
+		r.Log.Error(err, "failed to create the drift check job")
+		return ctrl.Result{}, err
+	}
+
+	r.Log.Info("checking terraform run for drift", "checkId", t.Status.Drift.CheckID)
+
+	err := r.Status().Update(ctx, t.Terraform)
+	return ctrl.Result{}, err
+}
+
+// handleDriftJobWatch monitors the status of a drift check job and records the drifted resources
+// it reported once completed.
+func (r *TerraformReconciler) handleDriftJobWatch(ctx context.Context, t *terraform.TerraformManipulator, job *batchv1.Job) (ctrl.Result, error) {
+	interval := t.Spec.DriftDetection.Interval.Duration
+
+	timeout, err := t.CheckJobTimeouts(ctx, r.Client, job, terraform.DriftJob)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// job timed out
+	if timeout != "" {
+		r.Recorder.Event(t, "Warning", "DriftCheckFailed", fmt.Sprintf("Run(%s) drift check timed out, %s", t.Status.RunID, timeout))
+
+		t.SetDriftCheckResult(v1alpha1.DriftCheckFailed, nil)
+
+		err := r.Status().Update(ctx, t.Terraform)
+		return ctrl.Result{RequeueAfter: interval}, err
+	}
+
+	// job failed
+	if job.Status.Failed > 0 {
+		r.Log.Error(errorscore.New("job failed"), "terraform drift check job failed to complete", "name", job.Name)
+
+		r.Recorder.Event(t, "Warning", "DriftCheckFailed", fmt.Sprintf("Run(%s) drift check failed", t.Status.RunID))
+
+		t.SetDriftCheckResult(v1alpha1.DriftCheckFailed, nil)
+
+		err := r.Status().Update(ctx, t.Terraform)
+		return ctrl.Result{RequeueAfter: interval}, err
+	}
+
+	// job is still running
+	if job.Status.Succeeded == 0 {
+		return ctrl.Result{RequeueAfter: r.requeueJobWatch}, nil
+	}
+
+	resources, err := t.GetDriftReport(ctx, r.Client, job)
+	if err != nil {
+		r.Log.Error(err, "failed to read the drift report", "name", job.Name)
+
+		r.Recorder.Event(t, "Warning", "DriftCheckFailed", fmt.Sprintf("Run(%s) drift report could not be read", t.Status.RunID))
+
+		t.SetDriftCheckResult(v1alpha1.DriftCheckFailed, nil)
+
+		err := r.Status().Update(ctx, t.Terraform)
+		return ctrl.Result{RequeueAfter: interval}, err
+	}
+
+	if t.Spec.DeleteCompletedJobs {
+		if err := t.DeleteDriftCheckJob(ctx, r.Client); err != nil {
+			r.Log.Error(err, "failed to delete terraform drift check job after completion", "name", job.Name)
+		}
+	}
+
+	if len(resources) == 0 {
+		t.SetDriftCheckResult(v1alpha1.DriftInSync, nil)
+
+		err := r.Status().Update(ctx, t.Terraform)
+		return ctrl.Result{RequeueAfter: interval}, err
+	}
+
+	t.SetDriftCheckResult(v1alpha1.DriftDrifted, resources)
+
+	count := fmt.Sprintf("%d", len(resources))
+	if resources[len(resources)-1] == terraform.DriftReportTruncated {
+		count = fmt.Sprintf("more than %d", len(resources)-1)
+	}
+
+	r.Recorder.Event(t, "Warning", "DriftDetected",
+		fmt.Sprintf("Run(%s) drift detected in %s resource(s)", t.Status.RunID, count))
+
+	if !t.Spec.DriftDetection.AutoRemediate {
+		err := r.Status().Update(ctx, t.Terraform)
+		return ctrl.Result{RequeueAfter: interval}, err
+	}
+
+	r.Recorder.Event(t, "Normal", "Remediating", "Creating a new run job to remediate the drift")
+	r.MetricsRecorder.RecordTotal(t.Name, t.Namespace)
+
+	return r.handleRunCreate(ctx, t, v1alpha1.TriggerDriftRemediation)
+}
+
 // handleRunJobWatch monitors the status of a Terraform job and updates the run status accordingly.
 // It checks if the job is still running, has succeeded, or has failed, and takes appropriate actions
 // such as cleaning up completed jobs, recording metrics, and updating the Terraform resource status.
 func (r *TerraformReconciler) handleRunJobWatch(ctx context.Context, t *terraform.TerraformManipulator) (ctrl.Result, error) {
-	job, err := t.GetJobForRun(ctx, r.Client, t.Status.RunID)
+	jobType := t.GetCurrentJobType()
+
+	job, err := t.GetJobForRun(ctx, r.Client, t.Status.RunID, jobType)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -254,16 +682,46 @@ func (r *TerraformReconciler) handleRunJobWatch(ctx context.Context, t *terrafor
 
 	defer r.MetricsRecorder.RecordDuration(t.Name, t.Namespace, startTime)
 
+	// job exceeded a timeout, or its pod is stuck pending
+	timeout, err := t.CheckJobTimeouts(ctx, r.Client, job, jobType)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if timeout != "" {
+		r.Log.Error(errorscore.New("job timed out"), "terraform run job timed out", "name", job.Name, "reason", timeout)
+
+		msg := fmt.Sprintf("Run(%s) timed out, %s", t.Status.RunID, timeout)
+		r.Recorder.Event(t, "Warning", "TimedOut", msg)
+
+		// Always bail out after updating the status
+		err = r.updateRunStatus(ctx, t, v1alpha1.RunFailed, msg)
+		return ctrl.Result{}, err
+	}
+
 	// job is still running
 	if job.Status.Active > 0 {
 		if t.IsRunning() {
 			return ctrl.Result{RequeueAfter: r.requeueJobWatch}, nil
 		}
 
-		r.Recorder.Event(t, "Normal", "Running", fmt.Sprintf("Run(%s) waiting for run job to finish", t.Status.RunID))
+		msg := fmt.Sprintf("Run(%s) waiting for run job to finish", t.Status.RunID)
+		r.Recorder.Event(t, "Normal", "Running", msg)
+
+		// Always bail out after updating the status
+		err := r.updateRunStatus(ctx, t, v1alpha1.RunRunning, msg)
+		return ctrl.Result{}, err
+	}
+
+	// plan is successful, keep the job around so the plan can be reviewed
+	if job.Status.Succeeded > 0 && jobType == terraform.PlanJob {
+		r.Log.Info("terraform plan job completed successfully")
+
+		msg := fmt.Sprintf("Run(%s) planned, annotate with '%s: %s' to apply it", t.Status.RunID, v1alpha1.ApproveRunAnnotation, t.Status.RunID)
+		r.Recorder.Event(t, "Normal", "AwaitingApproval", msg)
 
 		// Always bail out after updating the status
-		err := r.updateRunStatus(ctx, t, v1alpha1.RunRunning)
+		err := r.updateRunStatus(ctx, t, v1alpha1.RunAwaitingApproval, msg)
 		return ctrl.Result{}, err
 	}
 
@@ -281,14 +739,17 @@ func (r *TerraformReconciler) handleRunJobWatch(ctx context.Context, t *terrafor
 			}
 		}
 
+		var msg string
 		if t.Spec.Destroy {
-			r.Recorder.Event(t, "Normal", "Destroyed", fmt.Sprintf("Run(%s) completed with terraform destroy", t.Status.RunID))
+			msg = fmt.Sprintf("Run(%s) completed with terraform destroy", t.Status.RunID)
+			r.Recorder.Event(t, "Normal", "Destroyed", msg)
 		} else {
-			r.Recorder.Event(t, "Normal", "Completed", fmt.Sprintf("Run(%s) completed", t.Status.RunID))
+			msg = fmt.Sprintf("Run(%s) completed", t.Status.RunID)
+			r.Recorder.Event(t, "Normal", "Completed", msg)
 		}
 
 		// Always bail out after updating the status
-		err := r.updateRunStatus(ctx, t, v1alpha1.RunCompleted)
+		err := r.updateRunStatus(ctx, t, v1alpha1.RunCompleted, msg)
 		return ctrl.Result{}, err
 	}
 
@@ -296,10 +757,11 @@ func (r *TerraformReconciler) handleRunJobWatch(ctx context.Context, t *terrafor
 	if job.Status.Failed > 0 {
 		r.Log.Error(errorscore.New("job failed"), "terraform run job failed to complete", "name", job.Name)
 
-		r.Recorder.Event(t, "Warning", "Failed", fmt.Sprintf("Run(%s) failed", t.Status.RunID))
+		msg := fmt.Sprintf("Run(%s) failed, check the logs of job '%s'", t.Status.RunID, job.Name)
+		r.Recorder.Event(t, "Warning", "Failed", msg)
 
 		// Always bail out after updating the status
-		err = r.updateRunStatus(ctx, t, v1alpha1.RunFailed)
+		err = r.updateRunStatus(ctx, t, v1alpha1.RunFailed, msg)
 		return ctrl.Result{}, err
 	}
 
@@ -308,13 +770,16 @@ func (r *TerraformReconciler) handleRunJobWatch(ctx context.Context, t *terrafor
 }
 
 // updateRunStatus updates the status of a Terraform run with the provided status.
-// It sets the ObservedGeneration, manages timestamps for started/completed runs,
-// records metrics for specific statuses, and persists the status update to the cluster.
+// It sets the ObservedGeneration, the message, the conditions and the run history matching the status,
+// manages timestamps for started/completed runs, records metrics for specific statuses,
+// and persists the status update to the cluster.
 func (r *TerraformReconciler) updateRunStatus(
-	ctx context.Context, t *terraform.TerraformManipulator, status v1alpha1.TerraformRunStatus) error {
+	ctx context.Context, t *terraform.TerraformManipulator, status v1alpha1.TerraformRunStatus, message string) error {
 
 	t.Status.RunStatus = status
 	t.Status.ObservedGeneration = t.Generation
+	t.SetRunConditions(status, message)
+	t.SetRunHistoryStatus(status)
 
 	if status == v1alpha1.RunStarted {
 		t.Status.StartedTime = time.Now().Format(time.UnixDate)
@@ -327,7 +792,8 @@ func (r *TerraformReconciler) updateRunStatus(
 	}
 
 	// record the status only if completed/failed/waiting
-	if status == v1alpha1.RunCompleted || status == v1alpha1.RunFailed || status == v1alpha1.RunWaitingForDependency {
+	if status == v1alpha1.RunCompleted || status == v1alpha1.RunFailed ||
+		status == v1alpha1.RunWaitingForDependency || status == v1alpha1.RunAwaitingApproval {
 		r.MetricsRecorder.RecordStatus(t.Name, t.Namespace, status)
 	}
 
@@ -70,7 +70,7 @@ func (r *Recorder) RecordTotal(name string, namespace string) {
 func (r *Recorder) RecordStatus(name string, namespace string, status v1alpha1.TerraformRunStatus) {
 	var value float64
 
-	if status == v1alpha1.RunWaitingForDependency {
+	if status == v1alpha1.RunWaitingForDependency || status == v1alpha1.RunAwaitingApproval {
 		value = -1
 	}
 
@@ -60,6 +60,24 @@ var _ = Describe("Metrics Recorder", func() {
 			Expect(metricFamilies[0].Metric[0].Gauge.Value).To(Equal(&value))
 		})
 
+		It("should record the awaitingApproval status", func() {
+			rec.RecordStatus(name, namespace, v1alpha1.RunAwaitingApproval)
+
+			var (
+				value      float64 = -1.0
+				metricName string  = "tfo_workflow_status"
+			)
+
+			metricFamilies, err := reg.Gather()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metricFamilies).To(HaveLen(2))
+			Expect(metricFamilies[0].Name).To(Equal(&metricName))
+			Expect(metricFamilies[0].Metric).To(HaveLen(1))
+			Expect(metricFamilies[0].Metric[0].Gauge).ToNot(BeNil())
+			Expect(metricFamilies[0].Metric[0].Gauge.Value).To(Equal(&value))
+		})
+
 		It("should record the failed status", func() {
 			rec.RecordStatus(name, namespace, v1alpha1.RunFailed)
 
@@ -0,0 +1,95 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"github.com/rinswind/terraform-operator/internal/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("Run Approval", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.Spec.ApprovalMode = v1alpha1.ApprovalManual
+		t.Status.RunID = "abc123"
+	})
+
+	It("should only plan a run awaiting its approval", func() {
+		Expect(t.GetCurrentJobType()).To(Equal(PlanJob))
+
+		job, err := t.GetJobSpecForRun(PlanJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "TERRAFORM_PLAN_ONLY", Value: "true"}))
+	})
+
+	It("should not create a plan job with a runner image that doesn't support plan-only runs", func() {
+		utils.Env.TerraformRunnerFeatures = nil
+
+		_, err := t.GetJobSpecForRun(PlanJob)
+		Expect(err).To(MatchError(ContainSubstring("needs a runner image supporting the 'plan-only' feature")))
+
+		_, err = t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("Approver", func() {
+		var defaulter *TerraformDefaulter
+		var old *v1alpha1.Terraform
+
+		// the request of the user updating the workflow/run
+		update := func(username string) context.Context {
+			raw, err := json.Marshal(old)
+			Expect(err).ToNot(HaveOccurred())
+
+			return admission.NewContextWithRequest(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				OldObject: runtime.RawExtension{Raw: raw},
+				UserInfo:  authenticationv1.UserInfo{Username: username},
+			}})
+		}
+
+		BeforeEach(func() {
+			defaulter = &TerraformDefaulter{}
+			old = t.DeepCopy()
+			t.Annotations = map[string]string{}
+		})
+
+		It("should record the user who approves the run as the approver", func() {
+			t.Annotations[v1alpha1.ApproveRunAnnotation] = "abc123"
+			t.Annotations[v1alpha1.ApproverAnnotation] = "someone.else"
+
+			Expect(defaulter.Default(update("jane.doe"), t.Terraform)).To(Succeed())
+
+			runID, approver := t.GetApproval()
+			Expect(runID).To(Equal("abc123"))
+			Expect(approver).To(Equal("jane.doe"))
+
+			// the approver is kept by the following updates
+			old = t.DeepCopy()
+			t.Finalizers = []string{"finalizer.run.terraform-operator.io"}
+			Expect(defaulter.Default(update("system:serviceaccount:default:terraform-operator"), t.Terraform)).To(Succeed())
+			Expect(t.Annotations[v1alpha1.ApproverAnnotation]).To(Equal("jane.doe"))
+		})
+
+		It("should reject setting the approver of an approval", func() {
+			old.Annotations = map[string]string{v1alpha1.ApproveRunAnnotation: "abc123", v1alpha1.ApproverAnnotation: "jane.doe"}
+			t.Annotations = map[string]string{v1alpha1.ApproveRunAnnotation: "abc123", v1alpha1.ApproverAnnotation: "john.doe"}
+
+			Expect(defaulter.Default(update("john.doe"), t.Terraform)).To(MatchError(ContainSubstring("is set by the operator")))
+
+			// removing the approval is allowed
+			t.Annotations = map[string]string{}
+			Expect(defaulter.Default(update("john.doe"), t.Terraform)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,166 @@
+package terraform
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// typedBackendFileName is the Terraform project file holding the typed backend of the spec,
+	// it is written as Terraform JSON so the settings are always escaped
+	typedBackendFileName string = "backend.tf.json"
+
+	// Will be mounted in the runner container with the partial backend configurations read from secrets,
+	// passed to terraform init by TF_CLI_ARGS_init
+	backendConfigVolumeName string = "backend-config"
+	backendConfigMountPath  string = "/terraform/backend-config"
+
+	// Will be mounted in the runner container to hold the state of the local backend
+	backendStateVolumeName string = "backend-state"
+	backendStateMountPath  string = "/terraform/state"
+)
+
+// sensitiveBackendSettings are the settings of the typed backends that must be read from secrets
+var sensitiveBackendSettings = map[v1alpha1.BackendType][]string{
+	v1alpha1.BackendKubernetes: {"token", "password", "client_key"},
+	v1alpha1.BackendS3:         {"access_key", "secret_key", "token", "sse_customer_key"},
+	v1alpha1.BackendGCS:        {"credentials", "access_token", "encryption_key"},
+	v1alpha1.BackendAzureRM:    {"access_key", "sas_token", "client_secret", "client_certificate_password"},
+	v1alpha1.BackendHTTP:       {"password"},
+}
+
+// getTypedBackendFile generates the Terraform JSON file holding the typed backend of the workflow/run, the
+// kubernetes backend defaults to a secret in the namespace of the workflow/run and the local backend to its volume
+func (t *TerraformManipulator) getTypedBackendFile() ([]byte, error) {
+	backend := t.Spec.TypedBackend
+
+	if err := checkTypedBackend(backend); err != nil {
+		return nil, err
+	}
+
+	settings := map[string]interface{}{}
+
+	switch backend.Type {
+	case v1alpha1.BackendKubernetes:
+		settings["secret_suffix"] = t.Name
+		settings["namespace"] = t.Namespace
+		settings["in_cluster_config"] = true
+	case v1alpha1.BackendLocal:
+		settings["path"] = fmt.Sprintf("%s/terraform.tfstate", backendStateMountPath)
+		settings["workspace_dir"] = fmt.Sprintf("%s/terraform.tfstate.d", backendStateMountPath)
+	}
+
+	for key, value := range backend.Settings {
+		settings[key] = value
+	}
+
+	return marshalTerraformJSON(typedBackendFileName, map[string]interface{}{
+		"terraform": map[string]interface{}{
+			"backend": map[string]interface{}{
+				string(backend.Type): settings,
+			},
+		},
+	})
+}
+
+// checkTypedBackend checks the type of a typed backend, that its setting names are valid
+// and that its sensitive settings are not set in plain text
+func checkTypedBackend(backend *v1alpha1.TypedBackend) error {
+	if _, ok := sensitiveBackendSettings[backend.Type]; !ok && backend.Type != v1alpha1.BackendLocal {
+		return fmt.Errorf("unsupported backend type '%s'", backend.Type)
+	}
+
+	for key := range backend.Settings {
+		if !identifierRegex.MatchString(key) {
+			return fmt.Errorf("backend setting '%s' is not a valid setting name", key)
+		}
+
+		if slices.Contains(sensitiveBackendSettings[backend.Type], key) {
+			return fmt.Errorf("backend setting '%s' is sensitive, it must be set in a configFrom secret", key)
+		}
+	}
+
+	if backend.Type == v1alpha1.BackendLocal && backend.PersistentVolumeClaim == nil {
+		return fmt.Errorf("the local backend requires a persistentVolumeClaim to keep the state")
+	}
+
+	if backend.Type != v1alpha1.BackendLocal && backend.PersistentVolumeClaim != nil {
+		return fmt.Errorf("persistentVolumeClaim only applies to the local backend")
+	}
+
+	return nil
+}
+
+// getBackendEnvVars returns the environment variables passing the partial backend configurations
+// of the workflow/run to terraform init
+func (t *TerraformManipulator) getBackendEnvVars() []corev1.EnvVar {
+	if t.Spec.TypedBackend == nil || len(t.Spec.TypedBackend.ConfigFrom) == 0 {
+		return []corev1.EnvVar{}
+	}
+
+	args := []string{}
+	for i := range t.Spec.TypedBackend.ConfigFrom {
+		args = append(args, fmt.Sprintf("-backend-config=%s/%s", backendConfigMountPath, getBackendConfigFileName(i)))
+	}
+
+	return []corev1.EnvVar{getEnvVariable("TF_CLI_ARGS_init", strings.Join(args, " "))}
+}
+
+// getBackendVolumes returns the volumes of the partial backend configurations and of the state of the local backend
+func (t *TerraformManipulator) getBackendVolumes() []corev1.Volume {
+	volumes := []corev1.Volume{}
+
+	backend := t.Spec.TypedBackend
+	if backend == nil {
+		return volumes
+	}
+
+	if len(backend.ConfigFrom) > 0 {
+		sources := []corev1.VolumeProjection{}
+
+		for i := range backend.ConfigFrom {
+			sources = append(sources, getKeyProjection(nil, &backend.ConfigFrom[i], getBackendConfigFileName(i))...)
+		}
+
+		volumes = append(volumes, getVolumeSpec(backendConfigVolumeName, corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		}))
+	}
+
+	if backend.PersistentVolumeClaim != nil {
+		volumes = append(volumes, getVolumeSpec(backendStateVolumeName, corev1.VolumeSource{
+			PersistentVolumeClaim: backend.PersistentVolumeClaim,
+		}))
+	}
+
+	return volumes
+}
+
+// getBackendVolumeMounts returns the volume mounts of the partial backend configurations and of the state of the local backend
+func (t *TerraformManipulator) getBackendVolumeMounts() []corev1.VolumeMount {
+	mounts := []corev1.VolumeMount{}
+
+	backend := t.Spec.TypedBackend
+	if backend == nil {
+		return mounts
+	}
+
+	if len(backend.ConfigFrom) > 0 {
+		mounts = append(mounts, getVolumeMountSpec(backendConfigVolumeName, backendConfigMountPath, true))
+	}
+
+	if backend.PersistentVolumeClaim != nil {
+		mounts = append(mounts, getVolumeMountSpec(backendStateVolumeName, backendStateMountPath, backend.PersistentVolumeClaim.ReadOnly))
+	}
+
+	return mounts
+}
+
+// getBackendConfigFileName returns the file name of the i-th partial backend configuration
+func getBackendConfigFileName(i int) string {
+	return fmt.Sprintf("%d.tfbackend", i)
+}
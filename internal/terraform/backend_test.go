@@ -0,0 +1,94 @@
+package terraform
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Typed Backend", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+	})
+
+	It("should not default the backend configuration", func() {
+		t.Spec.TypedBackend = &v1alpha1.TypedBackend{Type: v1alpha1.BackendS3}
+
+		t.SetDefaults()
+
+		Expect(t.Spec.Backend).To(BeEmpty())
+	})
+
+	It("should generate the backend with its settings and pass the secret configurations to terraform init", func() {
+		config := corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "aws"}, Key: "backend.tfbackend"}
+		t.Spec.TypedBackend = &v1alpha1.TypedBackend{
+			Type:       v1alpha1.BackendS3,
+			Settings:   map[string]string{"bucket": "state", "key": "app/\"terraform.tfstate"},
+			ConfigFrom: []corev1.SecretKeySelector{config},
+		}
+
+		files, err := t.getTerraformProjectFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).ToNot(HaveKey(backendFileName))
+		Expect(files[typedBackendFileName]).To(MatchJSON(`{
+			"terraform": {"backend": {"s3": {"bucket": "state", "key": "app/\"terraform.tfstate"}}}
+		}`))
+
+		Expect(t.getBackendEnvVars()).To(Equal([]corev1.EnvVar{
+			{Name: "TF_CLI_ARGS_init", Value: "-backend-config=/terraform/backend-config/0.tfbackend"},
+		}))
+
+		volumes := t.getBackendVolumes()
+		Expect(volumes).To(HaveLen(1))
+		Expect(volumes[0].Name).To(Equal(backendConfigVolumeName))
+		Expect(volumes[0].Projected.Sources[0].Secret.Items).To(Equal([]corev1.KeyToPath{
+			{Key: "backend.tfbackend", Path: "0.tfbackend"},
+		}))
+	})
+
+	It("should default the kubernetes backend to a secret of the run", func() {
+		t.Spec.TypedBackend = &v1alpha1.TypedBackend{
+			Type:     v1alpha1.BackendKubernetes,
+			Settings: map[string]string{"namespace": "state"},
+		}
+
+		files, err := t.getTerraformProjectFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files[typedBackendFileName]).To(MatchJSON(`{
+			"terraform": {"backend": {"kubernetes": {"secret_suffix": "app", "namespace": "state", "in_cluster_config": true}}}
+		}`))
+	})
+
+	It("should keep the state of the local backend in its volume", func() {
+		t.Spec.TypedBackend = &v1alpha1.TypedBackend{
+			Type:                  v1alpha1.BackendLocal,
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "app-state"},
+		}
+
+		files, err := t.getTerraformProjectFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files[typedBackendFileName]).To(MatchJSON(`{
+			"terraform": {"backend": {"local": {
+				"path": "/terraform/state/terraform.tfstate",
+				"workspace_dir": "/terraform/state/terraform.tfstate.d"
+			}}}
+		}`))
+
+		Expect(t.getBackendVolumeMounts()).To(Equal([]corev1.VolumeMount{
+			{Name: backendStateVolumeName, MountPath: "/terraform/state"},
+		}))
+	})
+
+	It("should reject the sensitive settings", func() {
+		t.Spec.TypedBackend = &v1alpha1.TypedBackend{
+			Type:     v1alpha1.BackendAzureRM,
+			Settings: map[string]string{"client_secret": "secret"},
+		}
+
+		_, err := t.getTerraformProjectFiles()
+		Expect(err).To(MatchError(ContainSubstring("'client_secret' is sensitive")))
+	})
+})
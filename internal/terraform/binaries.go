@@ -0,0 +1,160 @@
+package terraform
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"github.com/rinswind/terraform-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// Will be mounted in the runner container with the cache of the engine binaries of the operator,
+	// laid out as <engine>/<version>/
+	engineBinariesVolumeName string = "engine-binaries"
+	engineBinariesMountPath  string = "/terraform/engines"
+)
+
+// engineRequirement is an engine binary needed by a workflow/run, with the spec field of its version
+// and the environment variable passing its directory in the binary cache to the runner
+type engineRequirement struct {
+	engine    v1alpha1.EngineType
+	version   string
+	path      *field.Path
+	dirEnvVar string
+}
+
+// engineBinaries holds the engine binaries of a workflow/run resolved from the operator config
+type engineBinaries struct {
+	// the runner image with the binaries installed, the default runner image if empty
+	image string
+	// the directories of the binaries in the binary cache
+	dirs []corev1.EnvVar
+}
+
+// getEngineRequirements returns the engine binaries the workflow/run needs, Terragrunt also needs Terraform
+func (t *TerraformManipulator) getEngineRequirements() []engineRequirement {
+	specPath := field.NewPath("spec")
+
+	versionPath := specPath.Child("terraformVersion")
+	if t.Spec.Engine != nil && t.Spec.Engine.Version != "" {
+		versionPath = specPath.Child("engine", "version")
+	}
+
+	requirements := []engineRequirement{
+		{engine: t.getEngineType(), version: t.getEngineVersion(), path: versionPath, dirEnvVar: "ENGINE_BINARY_DIR"},
+	}
+
+	if t.getEngineType() == v1alpha1.EngineTerragrunt {
+		requirements = append(requirements, engineRequirement{
+			engine: v1alpha1.EngineTerraform, version: t.Spec.TerraformVersion, path: specPath.Child("terraformVersion"), dirEnvVar: "TERRAFORM_BINARY_DIR",
+		})
+	}
+
+	return requirements
+}
+
+// getEngineBinaries resolves the engine binaries of the workflow/run from the runner images or the binary
+// cache of the operator config, the binaries are downloaded by the runner when the operator has none configured
+func (t *TerraformManipulator) getEngineBinaries() (*engineBinaries, error) {
+	binaries := &engineBinaries{dirs: []corev1.EnvVar{}}
+
+	if utils.Env.EngineBinaries == nil {
+		return binaries, nil
+	}
+
+	for _, r := range t.getEngineRequirements() {
+		image, cached, err := resolveEngineBinary(r.engine, r.version)
+		if err != nil {
+			return nil, err
+		}
+
+		if cached {
+			binaries.dirs = append(binaries.dirs, getEnvVariable(r.dirEnvVar, fmt.Sprintf("%s/%s/%s", engineBinariesMountPath, r.engine, r.version)))
+			continue
+		}
+
+		if binaries.image != "" && binaries.image != image {
+			return nil, fmt.Errorf("%s version '%s' resolves to image '%s' but the run already uses image '%s'", r.engine, r.version, image, binaries.image)
+		}
+		binaries.image = image
+	}
+
+	return binaries, nil
+}
+
+// resolveEngineBinary returns the runner image holding the binary of an engine version, or whether it is
+// held by the binary cache, the version must be available in one of them
+func resolveEngineBinary(engine v1alpha1.EngineType, version string) (string, bool, error) {
+	binaries := utils.Env.EngineBinaries
+
+	if image, ok := binaries.Images[string(engine)][version]; ok {
+		return image, false, nil
+	}
+
+	if binaries.Cache != nil && slices.Contains(binaries.Cache.Versions[string(engine)], version) {
+		return "", true, nil
+	}
+
+	available := getAvailableEngineVersions(engine)
+	if len(available) == 0 {
+		return "", false, fmt.Errorf("%s version '%s' is not available, the operator has no %s binaries", engine, version, engine)
+	}
+
+	return "", false, fmt.Errorf("%s version '%s' is not available, the available versions are %s", engine, version, strings.Join(available, ", "))
+}
+
+// getAvailableEngineVersions returns the sorted versions of an engine in the runner images and the binary cache
+func getAvailableEngineVersions(engine v1alpha1.EngineType) []string {
+	binaries := utils.Env.EngineBinaries
+
+	versions := slices.Collect(maps.Keys(binaries.Images[string(engine)]))
+	if binaries.Cache != nil {
+		versions = append(versions, binaries.Cache.Versions[string(engine)]...)
+	}
+
+	slices.Sort(versions)
+
+	return slices.Compact(versions)
+}
+
+// getImage returns the image of the runner container
+func (b *engineBinaries) getImage() string {
+	if b.image != "" {
+		return b.image
+	}
+
+	return getTerraformRunnerDockerImage()
+}
+
+// getEnvVars returns the environment variables telling the runner not to download the binaries,
+// and the directories of the binaries in the binary cache
+func (b *engineBinaries) getEnvVars() []corev1.EnvVar {
+	if utils.Env.EngineBinaries == nil {
+		return []corev1.EnvVar{}
+	}
+
+	return append([]corev1.EnvVar{getEnvVariable("ENGINE_PREINSTALLED", "true")}, b.dirs...)
+}
+
+// getVolumes returns the volume of the binary cache, if any binary is read from it
+func (b *engineBinaries) getVolumes() []corev1.Volume {
+	if len(b.dirs) == 0 {
+		return []corev1.Volume{}
+	}
+
+	return []corev1.Volume{getVolumeSpec(engineBinariesVolumeName, utils.Env.EngineBinaries.Cache.Volume)}
+}
+
+// getVolumeMounts returns the read-only mount of the binary cache, if any binary is read from it
+func (b *engineBinaries) getVolumeMounts() []corev1.VolumeMount {
+	if len(b.dirs) == 0 {
+		return []corev1.VolumeMount{}
+	}
+
+	return []corev1.VolumeMount{getVolumeMountSpec(engineBinariesVolumeName, engineBinariesMountPath, true)}
+}
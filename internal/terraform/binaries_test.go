@@ -0,0 +1,93 @@
+package terraform
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"github.com/rinswind/terraform-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Engine Binaries", func() {
+	var t *TerraformManipulator
+	BeforeEach(func() {
+		utils.Env = &utils.EnvConfig{
+			DockerRepository:        "docker.io",
+			TerraformRunnerImage:    "kubechamp/terraform-runner",
+			TerraformRunnerImageTag: "0.0.4",
+			EngineBinaries: &utils.EngineBinaries{
+				Images: map[string]map[string]string{
+					"terraform": {"1.5.7": "registry.local/terraform-runner:1.5.7"},
+				},
+				Cache: &utils.EngineBinaryCache{
+					Volume: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "engine-binaries"},
+					},
+					Versions: map[string][]string{
+						"terraform":  {"1.6.6"},
+						"opentofu":   {"1.8.2"},
+						"terragrunt": {"0.55.1"},
+					},
+				},
+			},
+		}
+
+		t = newTestTerraform()
+		t.Spec.TerraformVersion = "1.5.7"
+		t.Spec.Module.Version = "0.0.1"
+	})
+
+	It("should download the binaries at runtime when the operator has none configured", func() {
+		utils.Env.EngineBinaries = nil
+
+		job, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+
+		container := job.Spec.Template.Spec.Containers[0]
+		Expect(container.Image).To(Equal("docker.io/kubechamp/terraform-runner:0.0.4"))
+		Expect(container.Env).ToNot(ContainElement(HaveField("Name", "ENGINE_PREINSTALLED")))
+		Expect(job.Spec.Template.Spec.Volumes).ToNot(ContainElement(HaveField("Name", engineBinariesVolumeName)))
+	})
+
+	It("should run the runner image of the version", func() {
+		job, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+
+		container := job.Spec.Template.Spec.Containers[0]
+		Expect(container.Image).To(Equal("registry.local/terraform-runner:1.5.7"))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "ENGINE_PREINSTALLED", Value: "true"}))
+		Expect(job.Spec.Template.Spec.Volumes).ToNot(ContainElement(HaveField("Name", engineBinariesVolumeName)))
+	})
+
+	It("should mount the binary cache holding the versions", func() {
+		t.Spec.TerraformVersion = "1.6.6"
+		t.Spec.Engine = &v1alpha1.Engine{Type: v1alpha1.EngineTerragrunt, Version: "0.55.1"}
+
+		job, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+
+		pod := job.Spec.Template.Spec
+		Expect(pod.Containers[0].Image).To(Equal("docker.io/kubechamp/terraform-runner:0.0.4"))
+		Expect(pod.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "ENGINE_BINARY_DIR", Value: "/terraform/engines/terragrunt/0.55.1"},
+			corev1.EnvVar{Name: "TERRAFORM_BINARY_DIR", Value: "/terraform/engines/terraform/1.6.6"},
+		))
+		Expect(pod.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: engineBinariesVolumeName, MountPath: "/terraform/engines", ReadOnly: true,
+		}))
+		Expect(pod.InitContainers[0].VolumeMounts).ToNot(ContainElement(HaveField("Name", engineBinariesVolumeName)))
+		Expect(pod.Volumes).To(ContainElement(HaveField("Name", engineBinariesVolumeName)))
+	})
+
+	It("should fail on the versions that aren't available", func() {
+		t.Spec.Engine = &v1alpha1.Engine{Type: v1alpha1.EngineOpenTofu, Version: "1.9.0"}
+
+		_, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).To(MatchError("opentofu version '1.9.0' is not available, the available versions are 1.8.2"))
+
+		errs := t.Validate()
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.engine.version"))
+		Expect(errs[0].Detail).To(ContainSubstring(`"1.8.2"`))
+	})
+})
@@ -0,0 +1,171 @@
+package terraform
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"github.com/rinswind/terraform-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// cliConfigFileName is the Terraform CLI configuration file of the workflow/run, it's mounted
+// in the runner container with the generated project and pointed to by TF_CLI_CONFIG_FILE
+const cliConfigFileName string = "terraform.tfrc"
+
+// providerPatternRegex matches the provider source patterns of the provider installation methods,
+// e.g. `registry.example.com/*/*`
+var providerPatternRegex = regexp.MustCompile(`^[a-zA-Z0-9*._-]+(/[a-zA-Z0-9*._-]+){0,2}$`)
+
+// getTerraformCLIConfigFile generates the Terraform CLI configuration file of the workflow/run from its
+// provider installation methods, or returns the default CLI configuration of the operator, it returns
+// an empty string if there's none
+func (t *TerraformManipulator) getTerraformCLIConfigFile() (string, error) {
+	if t.Spec.CLIConfig == nil || len(t.Spec.CLIConfig.ProviderInstallation) == 0 {
+		return getDefaultCLIConfig(), nil
+	}
+
+	var b strings.Builder
+
+	b.WriteString("provider_installation {\n")
+
+	for i, m := range t.Spec.CLIConfig.ProviderInstallation {
+		if err := checkProviderInstallationMethod(m); err != nil {
+			return "", fmt.Errorf("invalid provider installation method %d: %w", i, err)
+		}
+
+		switch {
+		case m.NetworkMirror != "":
+			b.WriteString("  network_mirror {\n")
+			fmt.Fprintf(&b, "    url = %q\n", m.NetworkMirror)
+		case m.FilesystemMirror != "":
+			b.WriteString("  filesystem_mirror {\n")
+			fmt.Fprintf(&b, "    path = %q\n", m.FilesystemMirror)
+		default:
+			b.WriteString("  direct {\n")
+		}
+
+		if len(m.Include) > 0 {
+			fmt.Fprintf(&b, "    include = [%s]\n", quoteList(m.Include))
+		}
+
+		if len(m.Exclude) > 0 {
+			fmt.Fprintf(&b, "    exclude = [%s]\n", quoteList(m.Exclude))
+		}
+
+		b.WriteString("  }\n")
+	}
+
+	b.WriteString("}\n")
+
+	return b.String(), nil
+}
+
+// getCLIConfigEnvVars returns the environment variables pointing Terraform to the CLI configuration file and
+// holding the API tokens of the private registries of the workflow/run
+func (t *TerraformManipulator) getCLIConfigEnvVars() []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+
+	if t.hasCLIConfigFile() {
+		envVars = append(envVars, getEnvVariable("TF_CLI_CONFIG_FILE", fmt.Sprintf("%s/%s", moduleSourceMountPath, cliConfigFileName)))
+	}
+
+	if t.Spec.CLIConfig == nil {
+		return envVars
+	}
+
+	for _, c := range t.Spec.CLIConfig.Credentials {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:      getCLICredentialEnvVarName(c.Host),
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: c.TokenFrom},
+		})
+	}
+
+	return envVars
+}
+
+// hasCLIConfigFile evaluates if the generated project of the workflow/run holds a CLI configuration file
+func (t *TerraformManipulator) hasCLIConfigFile() bool {
+	return (t.Spec.CLIConfig != nil && len(t.Spec.CLIConfig.ProviderInstallation) > 0) || getDefaultCLIConfig() != ""
+}
+
+// getDefaultCLIConfig returns the default CLI configuration of the operator, empty if the operator has none
+// or its environment isn't loaded
+func getDefaultCLIConfig() string {
+	if utils.Env == nil {
+		return ""
+	}
+
+	return utils.Env.DefaultCLIConfig
+}
+
+// getCLICredentialEnvVarName returns the name of the environment variable holding the API token of a host,
+// the dots of the host are replaced by underscores and its dashes by double underscores
+func getCLICredentialEnvVarName(host string) string {
+	name := strings.ReplaceAll(host, "-", "__")
+	name = strings.ReplaceAll(name, ".", "_")
+
+	return fmt.Sprintf("TF_TOKEN_%s", name)
+}
+
+// checkCLICredentialHost checks that the host of a registry credential is a valid hostname
+func checkCLICredentialHost(host string) error {
+	if msgs := validation.IsDNS1123Subdomain(host); len(msgs) > 0 {
+		return fmt.Errorf("host '%s' is not a valid hostname: %s", host, strings.Join(msgs, ", "))
+	}
+
+	return nil
+}
+
+// checkProviderInstallationMethod checks that exactly one installation method is set
+// and that its values can be written to the CLI configuration
+func checkProviderInstallationMethod(m v1alpha1.ProviderInstallationMethod) error {
+	methods := 0
+
+	if m.NetworkMirror != "" {
+		methods++
+
+		u, err := url.Parse(m.NetworkMirror)
+		if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil || strings.ContainsAny(m.NetworkMirror, "\"\\${} \t\n") {
+			return fmt.Errorf("network mirror '%s' must be an https URL", m.NetworkMirror)
+		}
+	}
+
+	if m.FilesystemMirror != "" {
+		methods++
+
+		if !strings.HasPrefix(m.FilesystemMirror, "/") || strings.ContainsAny(m.FilesystemMirror, "\"\\${}\t\n") {
+			return fmt.Errorf("filesystem mirror '%s' must be an absolute path", m.FilesystemMirror)
+		}
+	}
+
+	if m.Direct {
+		methods++
+	}
+
+	if methods != 1 {
+		return fmt.Errorf("exactly one of networkMirror, filesystemMirror or direct is required")
+	}
+
+	for _, pattern := range append(append([]string{}, m.Include...), m.Exclude...) {
+		if !providerPatternRegex.MatchString(pattern) {
+			return fmt.Errorf("provider pattern '%s' must be a provider source address, e.g. registry.example.com/*/*", pattern)
+		}
+	}
+
+	return nil
+}
+
+// quoteList returns the comma separated list of the quoted values
+func quoteList(values []string) string {
+	quoted := []string{}
+
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+
+	return strings.Join(quoted, ", ")
+}
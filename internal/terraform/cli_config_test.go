@@ -0,0 +1,78 @@
+package terraform
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"github.com/rinswind/terraform-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Terraform CLI Configuration", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.Spec.Module.Source = "registry.example.com/platform/network/aws"
+	})
+
+	It("should generate the provider installation methods", func() {
+		t.Spec.CLIConfig = &v1alpha1.CLIConfig{
+			ProviderInstallation: []v1alpha1.ProviderInstallationMethod{
+				{NetworkMirror: "https://mirror.example.com/providers/", Include: []string{"registry.terraform.io/*/*"}},
+				{Direct: true, Exclude: []string{"registry.terraform.io/*/*"}},
+			},
+		}
+
+		files, err := t.getTerraformProjectFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files[cliConfigFileName]).To(Equal(`provider_installation {
+  network_mirror {
+    url = "https://mirror.example.com/providers/"
+    include = ["registry.terraform.io/*/*"]
+  }
+  direct {
+    exclude = ["registry.terraform.io/*/*"]
+  }
+}
+`))
+
+		Expect(t.getCLIConfigEnvVars()).To(Equal([]corev1.EnvVar{
+			{Name: "TF_CLI_CONFIG_FILE", Value: "/terraform/modules/terraform.tfrc"},
+		}))
+	})
+
+	It("should fall back to the default CLI configuration of the operator", func() {
+		utils.Env.DefaultCLIConfig = "plugin_cache_may_break_dependency_lock_file = true\n"
+
+		files, err := t.getTerraformProjectFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files[cliConfigFileName]).To(Equal(utils.Env.DefaultCLIConfig))
+	})
+
+	It("should pass the registry tokens as environment variables", func() {
+		token := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "registry"}, Key: "token"}
+		t.Spec.CLIConfig = &v1alpha1.CLIConfig{
+			Credentials: []v1alpha1.CLICredential{{Host: "tf-registry.example.com", TokenFrom: token}},
+		}
+
+		files, err := t.getTerraformProjectFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).ToNot(HaveKey(cliConfigFileName))
+
+		Expect(t.getCLIConfigEnvVars()).To(Equal([]corev1.EnvVar{
+			{Name: "TF_TOKEN_tf__registry_example_com", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: token}},
+		}))
+	})
+
+	It("should reject values breaking out of the CLI configuration", func() {
+		t.Spec.CLIConfig = &v1alpha1.CLIConfig{
+			ProviderInstallation: []v1alpha1.ProviderInstallationMethod{
+				{FilesystemMirror: "/mirror\"\n}\nplugin_cache_dir = \"/tmp"},
+			},
+		}
+
+		_, err := t.getTerraformProjectFiles()
+		Expect(err).To(MatchError(ContainSubstring("must be an absolute path")))
+	})
+})
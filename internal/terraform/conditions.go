@@ -0,0 +1,74 @@
+package terraform
+
+import (
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetRunConditions sets the status conditions and message that correspond to the status of the workflow/run
+func (t *TerraformManipulator) SetRunConditions(status v1alpha1.TerraformRunStatus, message string) {
+	t.Status.Message = message
+
+	// the changes of the current run are either planned (awaiting an approval) or applied
+	stage := v1alpha1.ConditionApplied
+	if t.GetCurrentJobType() == PlanJob {
+		stage = v1alpha1.ConditionPlanned
+	}
+
+	switch status {
+	case v1alpha1.RunWaitingForDependency:
+		t.setCondition(v1alpha1.ConditionReady, metav1.ConditionFalse, v1alpha1.ReasonDependencyNotReady, message)
+		t.setCondition(v1alpha1.ConditionReconciling, metav1.ConditionTrue, v1alpha1.ReasonDependencyNotReady, message)
+		t.setCondition(v1alpha1.ConditionDependenciesReady, metav1.ConditionFalse, v1alpha1.ReasonDependencyNotReady, message)
+		t.removeCondition(v1alpha1.ConditionStalled)
+
+	case v1alpha1.RunStarted, v1alpha1.RunRunning:
+		t.setCondition(v1alpha1.ConditionReady, metav1.ConditionFalse, v1alpha1.ReasonProgressing, message)
+		t.setCondition(v1alpha1.ConditionReconciling, metav1.ConditionTrue, v1alpha1.ReasonProgressing, message)
+		t.setCondition(v1alpha1.ConditionDependenciesReady, metav1.ConditionTrue, v1alpha1.ReasonSucceeded, "All dependencies are completed")
+		t.setCondition(stage, metav1.ConditionUnknown, v1alpha1.ReasonProgressing, message)
+		t.removeCondition(v1alpha1.ConditionStalled)
+
+	case v1alpha1.RunAwaitingApproval:
+		t.setCondition(v1alpha1.ConditionReady, metav1.ConditionFalse, v1alpha1.ReasonAwaitingApproval, message)
+		t.setCondition(v1alpha1.ConditionReconciling, metav1.ConditionTrue, v1alpha1.ReasonAwaitingApproval, message)
+		t.setCondition(v1alpha1.ConditionPlanned, metav1.ConditionTrue, v1alpha1.ReasonSucceeded, message)
+		t.removeCondition(v1alpha1.ConditionStalled)
+
+	case v1alpha1.RunCompleted:
+		t.setCondition(v1alpha1.ConditionReady, metav1.ConditionTrue, v1alpha1.ReasonSucceeded, message)
+		t.setCondition(v1alpha1.ConditionApplied, metav1.ConditionTrue, v1alpha1.ReasonSucceeded, message)
+		t.removeCondition(v1alpha1.ConditionReconciling)
+		t.removeCondition(v1alpha1.ConditionStalled)
+
+	case v1alpha1.RunFailed:
+		t.setCondition(v1alpha1.ConditionReady, metav1.ConditionFalse, v1alpha1.ReasonFailed, message)
+		t.setCondition(v1alpha1.ConditionStalled, metav1.ConditionTrue, v1alpha1.ReasonFailed, message)
+		if t.ObjectMeta.DeletionTimestamp.IsZero() {
+			t.setCondition(stage, metav1.ConditionFalse, v1alpha1.ReasonFailed, message)
+		}
+		t.removeCondition(v1alpha1.ConditionReconciling)
+
+	case v1alpha1.RunDeleting:
+		t.setCondition(v1alpha1.ConditionReady, metav1.ConditionFalse, v1alpha1.ReasonDeleting, message)
+		t.setCondition(v1alpha1.ConditionReconciling, metav1.ConditionTrue, v1alpha1.ReasonDeleting, message)
+		t.removeCondition(v1alpha1.ConditionStalled)
+	}
+}
+
+// setCondition sets a status condition for the current generation of the Terraform object
+func (t *TerraformManipulator) setCondition(conditionType string, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&t.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: t.Generation,
+	})
+}
+
+// removeCondition removes a status condition from the Terraform object
+func (t *TerraformManipulator) removeCondition(conditionType string) {
+	meta.RemoveStatusCondition(&t.Status.Conditions, conditionType)
+}
@@ -0,0 +1,77 @@
+package terraform
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Run Conditions", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		t = &TerraformManipulator{Terraform: &v1alpha1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "terraform-workflow", Namespace: "default", Generation: 2},
+		}}
+		t.Status.RunID = "abc123"
+	})
+
+	Context("Waiting for a dependency", func() {
+		It("should be reconciling with dependencies not ready", func() {
+			t.SetRunConditions(v1alpha1.RunWaitingForDependency, "dependency 'default/first' is not ready")
+
+			Expect(t.Status.Message).To(Equal("dependency 'default/first' is not ready"))
+			Expect(meta.IsStatusConditionFalse(t.Status.Conditions, v1alpha1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(t.Status.Conditions, v1alpha1.ConditionReconciling)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(t.Status.Conditions, v1alpha1.ConditionDependenciesReady)).To(BeTrue())
+
+			ready := meta.FindStatusCondition(t.Status.Conditions, v1alpha1.ConditionReady)
+			Expect(ready.Reason).To(Equal(v1alpha1.ReasonDependencyNotReady))
+			Expect(ready.ObservedGeneration).To(Equal(int64(2)))
+		})
+	})
+
+	Context("Completing a run", func() {
+		It("should be ready and no longer reconciling", func() {
+			t.SetRunConditions(v1alpha1.RunStarted, "Run(abc123) apply job started")
+			Expect(meta.FindStatusCondition(t.Status.Conditions, v1alpha1.ConditionApplied).Status).To(Equal(metav1.ConditionUnknown))
+
+			t.SetRunConditions(v1alpha1.RunCompleted, "Run(abc123) completed")
+
+			Expect(meta.IsStatusConditionTrue(t.Status.Conditions, v1alpha1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(t.Status.Conditions, v1alpha1.ConditionApplied)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(t.Status.Conditions, v1alpha1.ConditionDependenciesReady)).To(BeTrue())
+			Expect(meta.FindStatusCondition(t.Status.Conditions, v1alpha1.ConditionReconciling)).To(BeNil())
+			Expect(meta.FindStatusCondition(t.Status.Conditions, v1alpha1.ConditionStalled)).To(BeNil())
+		})
+	})
+
+	Context("Failing a run", func() {
+		It("should be stalled", func() {
+			t.SetRunConditions(v1alpha1.RunStarted, "Run(abc123) apply job started")
+			t.SetRunConditions(v1alpha1.RunFailed, "Run(abc123) failed")
+
+			Expect(meta.IsStatusConditionFalse(t.Status.Conditions, v1alpha1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(t.Status.Conditions, v1alpha1.ConditionStalled)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(t.Status.Conditions, v1alpha1.ConditionApplied)).To(BeTrue())
+			Expect(meta.FindStatusCondition(t.Status.Conditions, v1alpha1.ConditionReconciling)).To(BeNil())
+		})
+	})
+
+	Context("Planning a run that requires an approval", func() {
+		It("should be planned and awaiting approval", func() {
+			t.Spec.ApprovalMode = v1alpha1.ApprovalManual
+
+			t.SetRunConditions(v1alpha1.RunStarted, "Run(abc123) plan job started")
+			Expect(meta.FindStatusCondition(t.Status.Conditions, v1alpha1.ConditionPlanned).Status).To(Equal(metav1.ConditionUnknown))
+
+			t.SetRunConditions(v1alpha1.RunAwaitingApproval, "Run(abc123) planned")
+
+			Expect(meta.IsStatusConditionTrue(t.Status.Conditions, v1alpha1.ConditionPlanned)).To(BeTrue())
+			Expect(meta.FindStatusCondition(t.Status.Conditions, v1alpha1.ConditionReady).Reason).To(Equal(v1alpha1.ReasonAwaitingApproval))
+			Expect(meta.FindStatusCondition(t.Status.Conditions, v1alpha1.ConditionApplied)).To(BeNil())
+		})
+	})
+})
@@ -8,7 +8,7 @@ import (
 // getConfigMapSpecForModule returns a Kubernetes ConifgMap spec for the terraform module
 // This configmap will be mounted in the Terraform Runner pod
 func (t *TerraformManipulator) GetConfigMapSpecForModule() (*corev1.ConfigMap, error) {
-	tpl, err := t.getTerraformModuleFromTemplate()
+	files, err := t.getTerraformProjectFiles()
 	if err != nil {
 		return nil, err
 	}
@@ -26,9 +26,7 @@ func (t *TerraformManipulator) GetConfigMapSpecForModule() (*corev1.ConfigMap, e
 				t.getOwnerReference(),
 			},
 		},
-		Data: map[string]string{
-			"main.tf": string(tpl),
-		},
+		Data: files,
 	}
 
 	return cm, nil
@@ -0,0 +1,52 @@
+package terraform
+
+import (
+	"fmt"
+
+	"github.com/rinswind/terraform-operator/internal/utils"
+)
+
+// defaultWorkspace is the Terraform workspace used when none is set
+const defaultWorkspace string = "default"
+
+// SetDefaults fills in the defaults of the unset fields of the workflow/run spec
+// and returns true if any of them was set
+func (t *TerraformManipulator) SetDefaults() bool {
+	defaulted := false
+
+	if t.Spec.TerraformVersion == "" && utils.Env.DefaultTerraformVersion != "" {
+		t.Spec.TerraformVersion = utils.Env.DefaultTerraformVersion
+		defaulted = true
+	}
+
+	if t.Spec.Workspace == "" {
+		t.Spec.Workspace = defaultWorkspace
+		defaulted = true
+	}
+
+	// the backend is named after the object, which isn't known yet with a generated name
+	if t.Spec.Backend == "" && t.Spec.TypedBackend == nil && t.Name != "" {
+		t.setBackendCfgIfNotExist()
+		defaulted = true
+	}
+
+	return defaulted
+}
+
+// setBackendCfgIfNotExist sets the default backend to Kubernetes if not provided,
+// the state is stored in a secret in the namespace of the workflow/run
+func (t *TerraformManipulator) setBackendCfgIfNotExist() {
+	if t.Spec.Backend == "" {
+		t.Spec.Backend = t.getDefaultBackend()
+	}
+}
+
+// getDefaultBackend returns the default Kubernetes backend of the workflow/run
+func (t *TerraformManipulator) getDefaultBackend() string {
+	return fmt.Sprintf(`backend "kubernetes" {
+  secret_suffix     = "%s"
+  in_cluster_config = true
+  namespace         = "%s"
+}
+`, t.ObjectMeta.Name, t.ObjectMeta.Namespace)
+}
@@ -0,0 +1,43 @@
+package terraform
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/internal/utils"
+)
+
+var _ = Describe("Spec Defaults", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		utils.Env.DefaultTerraformVersion = "1.5.7"
+
+		t = newTestTerraform()
+		t.Spec.TerraformVersion = ""
+	})
+
+	Context("Unset fields", func() {
+		It("should set the default version, workspace and kubernetes backend", func() {
+			Expect(t.SetDefaults()).To(BeTrue())
+
+			Expect(t.Spec.TerraformVersion).To(Equal("1.5.7"))
+			Expect(t.Spec.Workspace).To(Equal("default"))
+			Expect(t.Spec.Backend).To(ContainSubstring(`backend "kubernetes"`))
+			Expect(t.Spec.Backend).To(ContainSubstring(`secret_suffix     = "app"`))
+
+			Expect(t.SetDefaults()).To(BeFalse())
+		})
+	})
+
+	Context("Set fields", func() {
+		It("should keep the values of the spec", func() {
+			t.Spec.TerraformVersion = "1.0.2"
+			t.Spec.Workspace = "dev"
+			t.Spec.Backend = `backend "local" {}`
+
+			Expect(t.SetDefaults()).To(BeFalse())
+			Expect(t.Spec.TerraformVersion).To(Equal("1.0.2"))
+			Expect(t.Spec.Backend).To(Equal(`backend "local" {}`))
+		})
+	})
+})
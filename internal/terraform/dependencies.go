@@ -2,14 +2,54 @@ package terraform
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
 
 	"github.com/rinswind/terraform-operator/api/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// DependsOnIndexKey is the field index of the Terraform objects by the namespaced names of their dependencies
+const DependsOnIndexKey string = ".spec.dependsOn"
+
+// dependencyOutputsLabel marks the secrets mirroring the outputs of a dependency in another namespace
+const dependencyOutputsLabel string = "dependencyOutputs"
+
+// dependencyOutputsPrefix prefixes the names of the secrets mirroring the outputs of a dependency in another
+// namespace, the names never end with the suffix of the output secrets of the workflows/runs
+const dependencyOutputsPrefix string = "dependency-outputs-"
+
+// IndexDependsOn returns the namespaced names of the dependencies of a Terraform object for the DependsOnIndexKey index
+func IndexDependsOn(obj client.Object) []string {
+	run, ok := obj.(*v1alpha1.Terraform)
+	if !ok {
+		return nil
+	}
+
+	names := []string{}
+
+	for _, d := range run.Spec.DependsOn {
+		if d == nil {
+			continue
+		}
+
+		namespace := d.Namespace
+		if namespace == "" {
+			namespace = run.Namespace
+		}
+
+		names = append(names, types.NamespacedName{Namespace: namespace, Name: d.Name}.String())
+	}
+
+	return names
+}
+
 func (t *TerraformManipulator) CheckDependencies(ctx context.Context, c client.Client) ([]TerraformManipulator, error) {
 	dependencies := []TerraformManipulator{}
 
@@ -36,48 +76,341 @@ func (t *TerraformManipulator) CheckDependencies(ctx context.Context, c client.C
 			return dependencies, fmt.Errorf("dependency '%s' is not ready", depName)
 		}
 
+		run := TerraformManipulator{Terraform: dep}
+
+		if len(t.getDependencyOutputKeys(&run)) > 0 && !run.GrantsOutputsTo(t.Namespace) {
+			return dependencies, fmt.Errorf("dependency '%s' doesn't grant its outputs to namespace '%s'", depName, t.Namespace)
+		}
+
+		dependencies = append(dependencies, run)
+	}
+
+	return dependencies, nil
+}
+
+// GetDependencies returns the dependencies of the workflow/run whatever their status is
+func (t *TerraformManipulator) GetDependencies(ctx context.Context, c client.Client) ([]TerraformManipulator, error) {
+	dependencies := []TerraformManipulator{}
+
+	for _, d := range t.Spec.DependsOn {
+		if d.Namespace == "" {
+			d.Namespace = t.Namespace
+		}
+
+		depName := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}
+
+		dep := &v1alpha1.Terraform{}
+
+		if err := c.Get(ctx, depName, dep); err != nil {
+			return dependencies, fmt.Errorf("unable to get '%s' dependency: %w", depName, err)
+		}
+
 		dependencies = append(dependencies, TerraformManipulator{Terraform: dep})
 	}
 
 	return dependencies, nil
 }
 
-// SetVariablesFromDependencies sets the variable from the output of a dependency
-// this currently only works with runs within the same namespace
+// GetDependents returns the Terraform objects that depend on the workflow/run, the objects are
+// looked up with the DependsOnIndexKey index that must be registered with the client
+func (t *TerraformManipulator) GetDependents(ctx context.Context, c client.Client) ([]TerraformManipulator, error) {
+	dependents := []TerraformManipulator{}
+
+	runs := &v1alpha1.TerraformList{}
+	name := types.NamespacedName{Namespace: t.Namespace, Name: t.Name}
+
+	if err := c.List(ctx, runs, client.MatchingFields{DependsOnIndexKey: name.String()}); err != nil {
+		return dependents, err
+	}
+
+	for i := range runs.Items {
+		dependents = append(dependents, TerraformManipulator{Terraform: &runs.Items[i]})
+	}
+
+	return dependents, nil
+}
+
+// SetDependencyOutputsHash records the hash of the dependency outputs used by the current workflow/run
+func (t *TerraformManipulator) SetDependencyOutputsHash(ctx context.Context, c client.Client, dependencies []TerraformManipulator) error {
+	hash, err := t.getDependencyOutputsHash(ctx, c, dependencies)
+	if err != nil {
+		return err
+	}
+
+	t.Status.DependencyOutputsHash = hash
+
+	return nil
+}
+
+// HaveDependencyOutputsChanged evaluates if the dependency outputs used by the workflow/run changed since
+// the current workflow/run was submitted, dependencies that are not completed are not considered changed yet
+func (t *TerraformManipulator) HaveDependencyOutputsChanged(ctx context.Context, c client.Client) (bool, error) {
+	dependencies, err := t.CheckDependencies(ctx, c)
+	if err != nil {
+		return false, nil
+	}
+
+	hash, err := t.getDependencyOutputsHash(ctx, c, dependencies)
+	if err != nil {
+		return false, err
+	}
+
+	return hash != t.Status.DependencyOutputsHash, nil
+}
+
+// RerunsOnDependencyChange evaluates if a new workflow/run is submitted when the dependency outputs change
+func (t *TerraformManipulator) RerunsOnDependencyChange() bool {
+	return t.Spec.RerunOnDependencyChange && len(t.Spec.DependsOn) > 0
+}
+
+// SetVariablesFromDependencies sets the variables from the outputs of the dependencies, the outputs of
+// a dependency in another namespace are read from the secret mirrored by MirrorDependencyOutputs
 func (t *TerraformManipulator) SetVariablesFromDependencies(dependencies []TerraformManipulator) {
 	if len(dependencies) == 0 {
 		return
 	}
 
-	for _, v := range t.Spec.Variables {
+	for i, v := range t.Spec.Variables {
 		if v.DependencyRef == nil {
 			continue
 		}
 
-		for index, dep := range dependencies {
-			if dep.Name != v.DependencyRef.Name || dep.Namespace != t.Namespace {
+		for _, dep := range dependencies {
+			if !t.isDependencyRefTo(v.DependencyRef, &dep) {
 				continue
 			}
 
-			tfVarRef := &v1.EnvVarSource{
+			t.Spec.Variables[i].ValueFrom = &v1.EnvVarSource{
 				SecretKeyRef: &v1.SecretKeySelector{
 					Key: v.DependencyRef.Key,
 					LocalObjectReference: v1.LocalObjectReference{
-						Name: dep.Status.OutputSecretName,
+						Name: t.getDependencyOutputSecretName(&dep),
 					},
 				},
 			}
+		}
+	}
+}
+
+// MirrorDependencyOutputs copies the outputs used by the workflow/run from the dependencies in other
+// namespaces to secrets in its own namespace, since a secret can only be referenced within a namespace
+func (t *TerraformManipulator) MirrorDependencyOutputs(ctx context.Context, c client.Client, dependencies []TerraformManipulator) error {
+	for i := range dependencies {
+		dep := &dependencies[i]
+
+		keys := t.getDependencyOutputKeys(dep)
+		if dep.Namespace == t.Namespace || len(keys) == 0 {
+			continue
+		}
+
+		if !dep.GrantsOutputsTo(t.Namespace) {
+			return fmt.Errorf("dependency '%s/%s' doesn't grant its outputs to namespace '%s'", dep.Namespace, dep.Name, t.Namespace)
+		}
+
+		source := &v1.Secret{}
 
-			tfVar := v1alpha1.Variable{
-				Key:           v.Key,
-				DependencyRef: v.DependencyRef,
-				ValueFrom:     tfVarRef,
+		if err := c.Get(ctx, types.NamespacedName{Namespace: dep.Namespace, Name: dep.Status.OutputSecretName}, source); err != nil {
+			return fmt.Errorf("unable to get the outputs of '%s/%s' dependency: %w", dep.Namespace, dep.Name, err)
+		}
+
+		data := map[string][]byte{}
+		for _, key := range keys {
+			if value, ok := source.Data[key]; ok {
+				data[key] = value
 			}
+		}
+
+		if err := t.createOrUpdateDependencyOutputSecret(ctx, c, dep, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteStaleDependencyOutputs deletes the secrets mirroring the outputs of the dependencies in other namespaces
+// that the workflow/run doesn't use anymore, that are gone or that don't grant their outputs to its namespace anymore
+func (t *TerraformManipulator) DeleteStaleDependencyOutputs(ctx context.Context, c client.Client) error {
+	secrets := &v1.SecretList{}
 
-			// remove the current variable from the list
-			t.Spec.Variables = append(t.Spec.Variables[:index], t.Spec.Variables[index+1:]...)
-			// add a new variable with the valueFrom
-			t.Spec.Variables = append(t.Spec.Variables, tfVar)
+	if err := c.List(ctx, secrets, client.InNamespace(t.Namespace), client.MatchingLabels{"terraformRunName": t.Name, dependencyOutputsLabel: "true"}); err != nil {
+		return err
+	}
+
+	if len(secrets.Items) == 0 {
+		return nil
+	}
+
+	used := map[string]bool{}
+
+	for _, d := range t.Spec.DependsOn {
+		namespace := d.Namespace
+		if namespace == "" || namespace == t.Namespace {
+			continue
+		}
+
+		dep := &v1alpha1.Terraform{}
+
+		err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: d.Name}, dep)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		run := &TerraformManipulator{Terraform: dep}
+
+		if len(t.getDependencyOutputKeys(run)) > 0 && run.GrantsOutputsTo(t.Namespace) {
+			used[t.getDependencyOutputSecretName(run)] = true
+		}
+	}
+
+	for i := range secrets.Items {
+		if used[secrets.Items[i].Name] || !t.isDependencyOutputSecret(&secrets.Items[i]) {
+			continue
+		}
+
+		if err := c.Delete(ctx, &secrets.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GrantsOutputsTo evaluates if the workflows/runs of the given namespace can use the outputs of the workflow/run
+func (t *TerraformManipulator) GrantsOutputsTo(namespace string) bool {
+	if namespace == t.Namespace {
+		return true
+	}
+
+	for _, g := range t.Spec.OutputGrants {
+		if g.Namespace == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDependencyRefTo evaluates if a variable dependency reference points to the given dependency,
+// the reference defaults to the namespace of the workflow/run
+func (t *TerraformManipulator) isDependencyRefTo(ref *v1alpha1.TerraformDependencyRef, dep *TerraformManipulator) bool {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = t.Namespace
+	}
+
+	return ref.Name == dep.Name && namespace == dep.Namespace
+}
+
+// getDependencyOutputKeys returns the output keys of the dependency used by the variables of the workflow/run
+func (t *TerraformManipulator) getDependencyOutputKeys(dep *TerraformManipulator) []string {
+	keys := []string{}
+
+	for _, v := range t.Spec.Variables {
+		if v.DependencyRef != nil && t.isDependencyRefTo(v.DependencyRef, dep) {
+			keys = append(keys, v.DependencyRef.Key)
 		}
 	}
+
+	return keys
+}
+
+// getDependencyOutputSecretName returns the name of the secret holding the outputs of the dependency
+// within the namespace of the workflow/run
+func (t *TerraformManipulator) getDependencyOutputSecretName(dep *TerraformManipulator) string {
+	if dep.Namespace == t.Namespace {
+		return dep.Status.OutputSecretName
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s", t.Name, dep.Namespace, dep.Name)))
+
+	return dependencyOutputsPrefix + hex.EncodeToString(hash[:16])
+}
+
+// isDependencyOutputSecret evaluates if the secret mirrors the outputs of a dependency for the workflow/run
+func (t *TerraformManipulator) isDependencyOutputSecret(secret *v1.Secret) bool {
+	return secret.Labels[dependencyOutputsLabel] == "true" && secret.Labels["terraformRunName"] == t.Name
+}
+
+// getDependencyOutputsHash returns the SHA-256 hash of the dependency outputs used by the variables of the
+// workflow/run, the hash is empty if no dependency output is used
+func (t *TerraformManipulator) getDependencyOutputsHash(ctx context.Context, c client.Client, dependencies []TerraformManipulator) (string, error) {
+	hash := sha256.New()
+	used := false
+
+	for i := range dependencies {
+		dep := &dependencies[i]
+
+		keys := t.getDependencyOutputKeys(dep)
+		if len(keys) == 0 {
+			continue
+		}
+
+		secret := &v1.Secret{}
+
+		if err := c.Get(ctx, types.NamespacedName{Namespace: dep.Namespace, Name: dep.Status.OutputSecretName}, secret); err != nil {
+			return "", fmt.Errorf("unable to get the outputs of '%s/%s' dependency: %w", dep.Namespace, dep.Name, err)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fmt.Fprintf(hash, "%s/%s/%s=", dep.Namespace, dep.Name, key)
+			hash.Write(secret.Data[key])
+			hash.Write([]byte{0})
+		}
+
+		used = true
+	}
+
+	if !used {
+		return "", nil
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// createOrUpdateDependencyOutputSecret creates or updates the secret mirroring the outputs of a dependency
+// in another namespace, the secret is owned by the workflow/run and any other secret with its name is left untouched
+func (t *TerraformManipulator) createOrUpdateDependencyOutputSecret(
+	ctx context.Context, c client.Client, dep *TerraformManipulator, data map[string][]byte) error {
+
+	secretName := types.NamespacedName{Namespace: t.Namespace, Name: t.getDependencyOutputSecretName(dep)}
+	secret := &v1.Secret{}
+
+	labels := getCommonLabels(t.Name, t.Status.RunID)
+	labels[dependencyOutputsLabel] = "true"
+
+	err := c.Get(ctx, secretName, secret)
+	if err == nil {
+		if !t.isDependencyOutputSecret(secret) {
+			return fmt.Errorf("unable to mirror the outputs of '%s/%s' dependency, secret '%s' isn't a mirror owned by the workflow/run",
+				dep.Namespace, dep.Name, secretName.Name)
+		}
+
+		secret.Labels = labels
+		secret.Data = data
+		return c.Update(ctx, secret)
+	}
+	if !errors.IsNotFound(err) {
+		return err
+	}
+
+	secret = &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName.Name,
+			Namespace: secretName.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{
+				t.getOwnerReference(),
+			},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: data,
+	}
+
+	return c.Create(ctx, secret)
 }
@@ -0,0 +1,228 @@
+package terraform
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Dependency Outputs", func() {
+	var t *TerraformManipulator
+	var network *TerraformManipulator
+	var c client.Client
+
+	BeforeEach(func() {
+		network = &TerraformManipulator{Terraform: &v1alpha1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "network", Namespace: "platform"},
+			Status:     v1alpha1.TerraformStatus{OutputSecretName: "network-outputs"},
+		}}
+
+		t = &TerraformManipulator{Terraform: &v1alpha1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a"},
+			Spec: v1alpha1.TerraformSpec{
+				DependsOn: []*v1alpha1.DependsOn{{Name: "network", Namespace: "platform"}},
+				Variables: []v1alpha1.Variable{
+					{Key: "vpc_id", DependencyRef: &v1alpha1.TerraformDependencyRef{Name: "network", Namespace: "platform", Key: "vpc_id"}},
+				},
+			},
+		}}
+
+		outputs := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "network-outputs", Namespace: "platform"},
+			Data:       map[string][]byte{"vpc_id": []byte("vpc-123"), "private_key": []byte("secret")},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(outputs).Build()
+	})
+
+	Context("Dependency in another namespace without a grant", func() {
+		It("should not mirror the outputs", func() {
+			err := t.MirrorDependencyOutputs(context.Background(), c, []TerraformManipulator{*network})
+
+			Expect(err).To(MatchError(ContainSubstring("doesn't grant its outputs to namespace 'team-a'")))
+		})
+	})
+
+	Context("Dependency in another namespace with a grant", func() {
+		It("should mirror the used outputs only and reference the mirrored secret", func() {
+			network.Spec.OutputGrants = []v1alpha1.OutputGrant{{Namespace: "team-a"}}
+			dependencies := []TerraformManipulator{*network}
+
+			Expect(t.MirrorDependencyOutputs(context.Background(), c, dependencies)).To(Succeed())
+			t.SetVariablesFromDependencies(dependencies)
+
+			mirrorName := t.getDependencyOutputSecretName(network)
+			Expect(mirrorName).To(HavePrefix("dependency-outputs-"))
+
+			mirror := &corev1.Secret{}
+			Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: mirrorName}, mirror)).To(Succeed())
+			Expect(mirror.Data).To(Equal(map[string][]byte{"vpc_id": []byte("vpc-123")}))
+
+			Expect(t.Spec.Variables[0].ValueFrom.SecretKeyRef.Name).To(Equal(mirrorName))
+			Expect(t.Spec.Variables[0].ValueFrom.SecretKeyRef.Key).To(Equal("vpc_id"))
+		})
+	})
+
+	Context("Dependency in another namespace with a secret of the mirror name", func() {
+		It("should leave the secret untouched", func() {
+			ctx := context.Background()
+			network.Spec.OutputGrants = []v1alpha1.OutputGrant{{Namespace: "team-a"}}
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      t.getDependencyOutputSecretName(network),
+					Namespace: "team-a",
+					Labels:    map[string]string{"terraformRunName": "other", "dependencyOutputs": "true"},
+				},
+				Data: map[string][]byte{"token": []byte("keep")},
+			}
+			Expect(c.Create(ctx, secret)).To(Succeed())
+
+			err := t.MirrorDependencyOutputs(ctx, c, []TerraformManipulator{*network})
+			Expect(err).To(MatchError(ContainSubstring("isn't a mirror owned by the workflow/run")))
+
+			found := &corev1.Secret{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(secret), found)).To(Succeed())
+			Expect(found.Data).To(Equal(map[string][]byte{"token": []byte("keep")}))
+		})
+
+		It("should not collide with the output secrets of the workflows/runs", func() {
+			other := &TerraformManipulator{Terraform: &v1alpha1.Terraform{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-platform-network", Namespace: "team-a"},
+			}}
+
+			Expect(t.getDependencyOutputSecretName(network)).NotTo(HaveSuffix("-outputs"))
+			Expect(t.getDependencyOutputSecretName(network)).NotTo(Equal(getOutputSecretName(other.Name)))
+		})
+	})
+
+	Context("Dependency in another namespace revoking its grant", func() {
+		It("should delete the mirrored outputs once the grant is revoked or the dependency dropped", func() {
+			ctx := context.Background()
+			mirrorName := types.NamespacedName{Namespace: "team-a", Name: t.getDependencyOutputSecretName(network)}
+
+			network.Spec.OutputGrants = []v1alpha1.OutputGrant{{Namespace: "team-a"}}
+
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			outputs := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "network-outputs", Namespace: "platform"},
+				Data:       map[string][]byte{"vpc_id": []byte("vpc-123")},
+			}
+
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(network.Terraform, outputs).Build()
+
+			Expect(t.MirrorDependencyOutputs(ctx, c, []TerraformManipulator{*network})).To(Succeed())
+
+			// the mirror of a granted dependency is kept
+			Expect(t.DeleteStaleDependencyOutputs(ctx, c)).To(Succeed())
+			Expect(c.Get(ctx, mirrorName, &corev1.Secret{})).To(Succeed())
+
+			network.Spec.OutputGrants = nil
+			Expect(c.Update(ctx, network.Terraform)).To(Succeed())
+
+			Expect(t.DeleteStaleDependencyOutputs(ctx, c)).To(Succeed())
+			Expect(errors.IsNotFound(c.Get(ctx, mirrorName, &corev1.Secret{}))).To(BeTrue())
+
+			network.Spec.OutputGrants = []v1alpha1.OutputGrant{{Namespace: "team-a"}}
+			Expect(c.Update(ctx, network.Terraform)).To(Succeed())
+			Expect(t.MirrorDependencyOutputs(ctx, c, []TerraformManipulator{*network})).To(Succeed())
+
+			t.Spec.DependsOn = nil
+			t.Spec.Variables = nil
+
+			Expect(t.DeleteStaleDependencyOutputs(ctx, c)).To(Succeed())
+			Expect(errors.IsNotFound(c.Get(ctx, mirrorName, &corev1.Secret{}))).To(BeTrue())
+		})
+	})
+
+	Context("Dependency outputs used by a completed run", func() {
+		It("should only be changed when a used output changes", func() {
+			ctx := context.Background()
+
+			network.Spec.OutputGrants = []v1alpha1.OutputGrant{{Namespace: "team-a"}}
+			network.Status.RunStatus = v1alpha1.RunCompleted
+
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			outputs := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "network-outputs", Namespace: "platform"},
+				Data:       map[string][]byte{"vpc_id": []byte("vpc-123"), "private_key": []byte("secret")},
+			}
+
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(network.Terraform, outputs).Build()
+
+			Expect(t.SetDependencyOutputsHash(ctx, c, []TerraformManipulator{*network})).To(Succeed())
+			Expect(t.Status.DependencyOutputsHash).ToNot(BeEmpty())
+
+			outputs.Data["private_key"] = []byte("rotated")
+			Expect(c.Update(ctx, outputs)).To(Succeed())
+			Expect(t.HaveDependencyOutputsChanged(ctx, c)).To(BeFalse())
+
+			outputs.Data["vpc_id"] = []byte("vpc-456")
+			Expect(c.Update(ctx, outputs)).To(Succeed())
+			Expect(t.HaveDependencyOutputsChanged(ctx, c)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Dependents", func() {
+	It("should find the dependents in any namespace through the index", func() {
+		network := &TerraformManipulator{Terraform: &v1alpha1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "network", Namespace: "platform"},
+		}}
+
+		app := &v1alpha1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a"},
+			Spec: v1alpha1.TerraformSpec{
+				DependsOn: []*v1alpha1.DependsOn{{Name: "network", Namespace: "platform"}},
+			},
+		}
+
+		dns := &v1alpha1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "platform"},
+			Spec: v1alpha1.TerraformSpec{
+				DependsOn: []*v1alpha1.DependsOn{{Name: "network"}},
+			},
+		}
+
+		unrelated := &v1alpha1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "team-a"},
+			Spec: v1alpha1.TerraformSpec{
+				DependsOn: []*v1alpha1.DependsOn{{Name: "network"}},
+			},
+		}
+
+		Expect(IndexDependsOn(dns)).To(Equal([]string{"platform/network"}))
+
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(network.Terraform, app, dns, unrelated).
+			WithIndex(&v1alpha1.Terraform{}, DependsOnIndexKey, IndexDependsOn).
+			Build()
+
+		dependents, err := network.GetDependents(context.Background(), c)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dependents).To(HaveLen(2))
+	})
+})
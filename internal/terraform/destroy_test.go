@@ -0,0 +1,79 @@
+package terraform
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Run Destroy", func() {
+	var t *TerraformManipulator
+	var c client.Client
+
+	ctx := context.Background()
+
+	newJob := func(jobType RunJobType, status batchv1.JobStatus) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      t.getJobNameForRun(t.Status.RunID, jobType),
+				Namespace: "default",
+				Labels:    getCommonLabels(t.Name, t.Status.RunID),
+			},
+			Status: status,
+		}
+	}
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.UID = "app-uid"
+		t.Status.RunID = "abc123"
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(batchv1.AddToScheme(scheme)).To(Succeed())
+		Expect(rbacv1.AddToScheme(scheme)).To(Succeed())
+
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+	})
+
+	It("should stop the unfinished jobs of the run only", func() {
+		apply := newJob(ApplyJob, batchv1.JobStatus{Active: 1})
+		plan := newJob(PlanJob, batchv1.JobStatus{
+			Succeeded:  1,
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		})
+		Expect(c.Create(ctx, apply)).To(Succeed())
+		Expect(c.Create(ctx, plan)).To(Succeed())
+
+		stopped, err := t.StopRunJobs(ctx, c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stopped).To(BeFalse())
+
+		Expect(errors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(apply), &batchv1.Job{}))).To(BeTrue())
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(plan), &batchv1.Job{})).To(Succeed())
+
+		stopped, err = t.StopRunJobs(ctx, c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stopped).To(BeTrue())
+	})
+
+	It("should recreate the ConfigMap of the run for the destroy job", func() {
+		job, err := t.CreateDestroyJob(ctx, c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(job.Name).To(Equal(t.getJobNameForRun("abc123", DestroyJob)))
+
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: getUniqueResourceName("app", "abc123"), Namespace: "default"}, configMap)).To(Succeed())
+		Expect(configMap.Data).To(HaveKey("main.tf.json"))
+	})
+})
@@ -0,0 +1,162 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	errorscore "errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DriftReportTruncated ends the drifted resources of a drift report cut by the termination message limit
+const DriftReportTruncated string = "(truncated, the drift report exceeded the termination message limit)"
+
+// driftReport is the report the Terraform Runner writes as the termination message of a drift check, the
+// kubelet only keeps the first 4096 bytes of it
+type driftReport struct {
+	// The addresses of the resources that changed outside of Terraform
+	Resources []string `json:"resources"`
+}
+
+// HasDriftDetection evaluates if the workflow/run should be periodically checked for drift
+func (t *TerraformManipulator) HasDriftDetection() bool {
+	return t.Spec.DriftDetection != nil && t.Spec.DriftDetection.Interval.Duration > 0 && !t.Spec.Destroy
+}
+
+// IsCheckingDrift evaluates that a drift check of the workflow/run is in progress
+func (t *TerraformManipulator) IsCheckingDrift() bool {
+	return t.Status.Drift != nil && t.Status.Drift.Status == v1alpha1.DriftChecking
+}
+
+// GetNextDriftCheckTime returns the time at which the next drift check is due,
+// the interval starts from the completion of the run or from the last drift check
+func (t *TerraformManipulator) GetNextDriftCheckTime() time.Time {
+	last, _ := time.Parse(time.UnixDate, t.Status.CompletionTime)
+
+	if t.Status.Drift != nil {
+		if lastCheck, err := time.Parse(time.UnixDate, t.Status.Drift.LastCheckTime); err == nil && lastCheck.After(last) {
+			last = lastCheck
+		}
+	}
+
+	return last.Add(t.Spec.DriftDetection.Interval.Duration)
+}
+
+// CheckDriftDetectionSupport checks that the runner image supports the drift check jobs
+func (t *TerraformManipulator) CheckDriftDetectionSupport() error {
+	return checkRunnerFeatures(DriftJob)
+}
+
+// StartDriftCheck creates the Kubernetes Job that checks the current workflow/run for drift
+func (t *TerraformManipulator) StartDriftCheck(ctx context.Context, c client.Client) (*batchv1.Job, error) {
+	var resources []string
+
+	// delete the job of the previous check
+	if t.Status.Drift != nil {
+		if err := t.deleteJobByRun(ctx, c, t.Status.RunID, DriftJob); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+
+		resources = t.Status.Drift.Resources
+	}
+
+	t.Status.Drift = &v1alpha1.DriftDetectionStatus{
+		CheckID:   random(6),
+		Status:    v1alpha1.DriftChecking,
+		Resources: resources,
+	}
+
+	return t.createJobForRun(ctx, c, DriftJob)
+}
+
+// DeleteDriftCheckJob removes the Kubernetes Job of the current drift check
+func (t *TerraformManipulator) DeleteDriftCheckJob(ctx context.Context, c client.Client) error {
+	return t.deleteJobByRun(ctx, c, t.Status.RunID, DriftJob)
+}
+
+// SetDriftCheckResult records the result of the current drift check, or of a drift check that couldn't start
+func (t *TerraformManipulator) SetDriftCheckResult(status v1alpha1.DriftStatus, resources []string) {
+	if t.Status.Drift == nil {
+		t.Status.Drift = &v1alpha1.DriftDetectionStatus{}
+	}
+
+	t.Status.Drift.Status = status
+	t.Status.Drift.Resources = resources
+	t.Status.Drift.LastCheckTime = time.Now().Format(time.UnixDate)
+}
+
+// GetDriftReport returns the addresses of the drifted resources reported by a successful drift check job
+func (t *TerraformManipulator) GetDriftReport(ctx context.Context, c client.Client, job *batchv1.Job) ([]string, error) {
+	pods := &corev1.PodList{}
+
+	if err := c.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != runnerContainerName || status.State.Terminated == nil {
+				continue
+			}
+
+			report := &driftReport{}
+
+			if status.State.Terminated.Message == "" {
+				return report.Resources, nil
+			}
+
+			if err := json.Unmarshal([]byte(status.State.Terminated.Message), report); err != nil {
+				// the report was cut past the termination message limit
+				if resources, ok := parseTruncatedDriftReport(status.State.Terminated.Message); ok {
+					return append(resources, DriftReportTruncated), nil
+				}
+
+				return nil, fmt.Errorf("unable to parse the drift report of '%s': %w", pod.Name, err)
+			}
+
+			return report.Resources, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no drift report found for job '%s'", job.Name)
+}
+
+// parseTruncatedDriftReport returns the complete resource addresses of a drift report cut short in its list of
+// resources, not ok if the report is malformed before that
+func parseTruncatedDriftReport(message string) ([]string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(message))
+
+	for _, expected := range []json.Token{json.Delim('{'), "resources", json.Delim('[')} {
+		if token, err := decoder.Token(); err != nil || token != expected {
+			return nil, false
+		}
+	}
+
+	resources := []string{}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return resources, errorscore.Is(err, io.ErrUnexpectedEOF) || errorscore.Is(err, io.EOF)
+		}
+
+		address, ok := token.(string)
+		if !ok {
+			return nil, false
+		}
+
+		resources = append(resources, address)
+	}
+}
@@ -0,0 +1,112 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"github.com/rinswind/terraform-operator/internal/utils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Drift Report", func() {
+	var t *TerraformManipulator
+	var c client.Client
+
+	ctx := context.Background()
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "app-drift", Namespace: "default"}}
+
+	// the kubelet keeps the first 4096 bytes of the termination message
+	newPod := func(message string) *corev1.Pod {
+		if len(message) > 4096 {
+			message = message[:4096]
+		}
+
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "app-drift-pod",
+				Namespace: "default",
+				Labels:    map[string]string{batchv1.JobNameLabel: job.Name},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  runnerContainerName,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+				}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+	})
+
+	It("should return the drifted resources of the report", func() {
+		Expect(c.Create(ctx, newPod(`{"resources":["aws_s3_bucket.this"]}`))).To(Succeed())
+
+		resources, err := t.GetDriftReport(ctx, c, job)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resources).To(Equal([]string{"aws_s3_bucket.this"}))
+	})
+
+	It("should keep the complete resources of a report larger than the termination message", func() {
+		report := driftReport{}
+		for i := 0; i < 200; i++ {
+			report.Resources = append(report.Resources, fmt.Sprintf("module.operator.aws_security_group.this[%d]", i))
+		}
+
+		raw, err := json.Marshal(report)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(raw)).To(BeNumerically(">", 4096))
+
+		Expect(c.Create(ctx, newPod(string(raw)))).To(Succeed())
+
+		resources, err := t.GetDriftReport(ctx, c, job)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(resources)).To(BeNumerically(">", 1))
+		Expect(resources[len(resources)-1]).To(Equal(DriftReportTruncated))
+		Expect(resources[:len(resources)-1]).To(Equal(report.Resources[:len(resources)-1]))
+	})
+
+	It("should reject a malformed report", func() {
+		Expect(c.Create(ctx, newPod(`{"resources":[1`))).To(Succeed())
+
+		_, err := t.GetDriftReport(ctx, c, job)
+		Expect(err).To(MatchError(ContainSubstring("unable to parse the drift report of 'app-drift-pod'")))
+	})
+
+	It("should only start the drift checks with a runner declaring the drift report support", func() {
+		job, err := t.GetJobSpecForRun(DriftJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "TERRAFORM_PLAN_ONLY", Value: "true"},
+			corev1.EnvVar{Name: "TERRAFORM_REFRESH_ONLY", Value: "true"},
+			corev1.EnvVar{Name: "TERRAFORM_DRIFT_REPORT_PATH", Value: corev1.TerminationMessagePathDefault},
+		))
+
+		utils.Env.TerraformRunnerFeatures = []string{RunnerFeaturePlanOnly}
+
+		Expect(t.CheckDriftDetectionSupport()).To(MatchError(ContainSubstring("'drift-report' feature")))
+		_, err = t.GetJobSpecForRun(DriftJob)
+		Expect(err).To(HaveOccurred())
+
+		// the check that couldn't start is recorded as failed
+		t.SetDriftCheckResult(v1alpha1.DriftCheckFailed, nil)
+		Expect(t.Status.Drift.Status).To(Equal(v1alpha1.DriftCheckFailed))
+		Expect(t.Status.Drift.LastCheckTime).ToNot(BeEmpty())
+	})
+})
@@ -0,0 +1,144 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// terragruntConfigFileName is the Terragrunt configuration generated for the Terragrunt engine, it is
+// written as JSON and only holds the version constraint since the project is generated by the operator
+const terragruntConfigFileName string = "terragrunt.hcl.json"
+
+// engineVersionRegex matches the exact versions installed by the runner, e.g. `1.8.2` or `1.9.0-beta1`
+var engineVersionRegex = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?$`)
+
+// versionConstraintRegex matches the version constraints, e.g. `~> 1.8` or `>= 1.8, < 2.0`
+var versionConstraintRegex = regexp.MustCompile(`^\s*(=|!=|>|>=|<|<=|~>)?\s*v?[0-9]+(\.[0-9]+){0,2}(-[0-9A-Za-z.-]+)?\s*(,\s*(=|!=|>|>=|<|<=|~>)?\s*v?[0-9]+(\.[0-9]+){0,2}(-[0-9A-Za-z.-]+)?\s*)*$`)
+
+// getEngineType returns the engine running the workflow/run, Terraform unless set
+func (t *TerraformManipulator) getEngineType() v1alpha1.EngineType {
+	if t.Spec.Engine == nil || t.Spec.Engine.Type == "" {
+		return v1alpha1.EngineTerraform
+	}
+
+	return t.Spec.Engine.Type
+}
+
+// getEngineVersion returns the version of the engine of the workflow/run, Terraform defaults to the
+// Terraform version of the spec
+func (t *TerraformManipulator) getEngineVersion() string {
+	if t.Spec.Engine != nil && t.Spec.Engine.Version != "" {
+		return t.Spec.Engine.Version
+	}
+
+	if t.getEngineType() == v1alpha1.EngineTerraform {
+		return t.Spec.TerraformVersion
+	}
+
+	return ""
+}
+
+// getTerraformVersion returns the version of Terraform installed by the runner, OpenTofu doesn't need Terraform
+func (t *TerraformManipulator) getTerraformVersion() string {
+	switch t.getEngineType() {
+	case v1alpha1.EngineOpenTofu:
+		return ""
+	case v1alpha1.EngineTerragrunt:
+		return t.Spec.TerraformVersion
+	default:
+		return t.getEngineVersion()
+	}
+}
+
+// getEngineVersionConstraint returns the version constraint of the engine of the workflow/run
+func (t *TerraformManipulator) getEngineVersionConstraint() string {
+	if t.Spec.Engine != nil && t.Spec.Engine.VersionConstraint != "" {
+		return t.Spec.Engine.VersionConstraint
+	}
+
+	return fmt.Sprintf("~> %s", t.getEngineVersion())
+}
+
+// getRequiredVersion returns the `required_version` of the project, the version constraint of the engine
+// or of the Terraform version wrapped by Terragrunt
+func (t *TerraformManipulator) getRequiredVersion() string {
+	if t.getEngineType() == v1alpha1.EngineTerragrunt {
+		return fmt.Sprintf("~> %s", t.Spec.TerraformVersion)
+	}
+
+	return t.getEngineVersionConstraint()
+}
+
+// getTerragruntConfigFile generates the Terragrunt configuration of the workflow/run, empty unless it runs with Terragrunt
+func (t *TerraformManipulator) getTerragruntConfigFile() ([]byte, error) {
+	if t.getEngineType() != v1alpha1.EngineTerragrunt {
+		return nil, nil
+	}
+
+	return marshalTerraformJSON(terragruntConfigFileName, map[string]interface{}{
+		"terragrunt_version_constraint": t.getEngineVersionConstraint(),
+	})
+}
+
+// checkEngine checks that the engine of the workflow/run has the versions it requires and
+// that its settings apply to it
+func (t *TerraformManipulator) checkEngine() error {
+	engine := t.getEngineType()
+
+	switch engine {
+	case v1alpha1.EngineTerraform, v1alpha1.EngineOpenTofu, v1alpha1.EngineTerragrunt:
+	default:
+		return fmt.Errorf("unsupported engine '%s'", engine)
+	}
+
+	if t.getEngineVersion() == "" {
+		return fmt.Errorf("the version of the %s engine is required", engine)
+	}
+
+	if engine == v1alpha1.EngineTerragrunt && t.Spec.TerraformVersion == "" {
+		return fmt.Errorf("the terraform version wrapped by the terragrunt engine is required")
+	}
+
+	if t.Spec.Engine == nil {
+		return nil
+	}
+
+	if t.Spec.Engine.Version != "" && !engineVersionRegex.MatchString(t.Spec.Engine.Version) {
+		return fmt.Errorf("engine version '%s' is not an exact version", t.Spec.Engine.Version)
+	}
+
+	if t.Spec.Engine.VersionConstraint != "" && !versionConstraintRegex.MatchString(t.Spec.Engine.VersionConstraint) {
+		return fmt.Errorf("engine version constraint '%s' is not a valid version constraint", t.Spec.Engine.VersionConstraint)
+	}
+
+	if t.Spec.Engine.Encryption != nil && engine != v1alpha1.EngineOpenTofu {
+		return fmt.Errorf("encryption only applies to the opentofu engine")
+	}
+
+	return nil
+}
+
+// getEngineEnvVars returns the environment variables selecting the engine of the runner and its versions,
+// and the encryption configuration of OpenTofu
+func (t *TerraformManipulator) getEngineEnvVars() []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+
+	if version := t.getTerraformVersion(); version != "" {
+		envVars = append(envVars, getEnvVariable("TERRAFORM_VERSION", version))
+	}
+
+	envVars = append(envVars, getEnvVariable("ENGINE", string(t.getEngineType())))
+	envVars = append(envVars, getEnvVariable("ENGINE_VERSION", t.getEngineVersion()))
+
+	if t.Spec.Engine != nil && t.Spec.Engine.Encryption != nil {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:      "TF_ENCRYPTION",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: t.Spec.Engine.Encryption.ConfigFrom.DeepCopy()},
+		})
+	}
+
+	return envVars
+}
@@ -0,0 +1,96 @@
+package terraform
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Engine", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.Spec.TerraformVersion = "1.5.7"
+		t.Spec.Module.Version = "0.0.1"
+	})
+
+	getRequiredVersion := func(files map[string]string) interface{} {
+		project := map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(files[mainFileName]), &project)).To(Succeed())
+
+		return project["terraform"].(map[string]interface{})["required_version"]
+	}
+
+	getEnvVar := func(name string) *corev1.EnvVar {
+		for _, e := range t.getRunnerSpecificEnvVars(ApplyJob) {
+			if e.Name == name {
+				return &e
+			}
+		}
+
+		return nil
+	}
+
+	It("should run with Terraform by default", func() {
+		files, err := t.getTerraformProjectFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getRequiredVersion(files)).To(Equal("~> 1.5.7"))
+
+		Expect(getEnvVar("TERRAFORM_VERSION").Value).To(Equal("1.5.7"))
+		Expect(getEnvVar("ENGINE").Value).To(Equal("terraform"))
+		Expect(getEnvVar("ENGINE_VERSION").Value).To(Equal("1.5.7"))
+	})
+
+	It("should run with OpenTofu with its own version constraint and encryption", func() {
+		t.Spec.Engine = &v1alpha1.Engine{
+			Type:              v1alpha1.EngineOpenTofu,
+			Version:           "1.8.2",
+			VersionConstraint: ">= 1.8, < 2.0",
+			Encryption: &v1alpha1.EngineEncryption{
+				ConfigFrom: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tofu"}, Key: "encryption.hcl"},
+			},
+		}
+
+		files, err := t.getTerraformProjectFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getRequiredVersion(files)).To(Equal(">= 1.8, < 2.0"))
+		Expect(files).ToNot(HaveKey(terragruntConfigFileName))
+
+		Expect(getEnvVar("TERRAFORM_VERSION")).To(BeNil())
+		Expect(getEnvVar("ENGINE").Value).To(Equal("opentofu"))
+		Expect(getEnvVar("ENGINE_VERSION").Value).To(Equal("1.8.2"))
+		Expect(getEnvVar("TF_ENCRYPTION").ValueFrom.SecretKeyRef.Name).To(Equal("tofu"))
+	})
+
+	It("should run with Terragrunt wrapping Terraform", func() {
+		t.Spec.Engine = &v1alpha1.Engine{Type: v1alpha1.EngineTerragrunt, Version: "0.55.1"}
+
+		files, err := t.getTerraformProjectFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getRequiredVersion(files)).To(Equal("~> 1.5.7"))
+		Expect(files[terragruntConfigFileName]).To(MatchJSON(`{"terragrunt_version_constraint": "~> 0.55.1"}`))
+
+		Expect(getEnvVar("TERRAFORM_VERSION").Value).To(Equal("1.5.7"))
+		Expect(getEnvVar("ENGINE").Value).To(Equal("terragrunt"))
+		Expect(getEnvVar("ENGINE_VERSION").Value).To(Equal("0.55.1"))
+	})
+
+	It("should reject engines without their versions or with settings of another engine", func() {
+		t.Spec.Engine = &v1alpha1.Engine{Type: v1alpha1.EngineOpenTofu}
+		_, err := t.getTerraformProjectFiles()
+		Expect(err).To(MatchError(ContainSubstring("the version of the opentofu engine is required")))
+
+		t.Spec.Engine = &v1alpha1.Engine{Type: v1alpha1.EngineTerragrunt, Version: "0.55.1"}
+		t.Spec.TerraformVersion = ""
+		_, err = t.getTerraformProjectFiles()
+		Expect(err).To(MatchError(ContainSubstring("terraform version wrapped by the terragrunt engine is required")))
+
+		t.Spec.Engine = &v1alpha1.Engine{Version: "1.6.0", Encryption: &v1alpha1.EngineEncryption{}}
+		_, err = t.getTerraformProjectFiles()
+		Expect(err).To(MatchError(ContainSubstring("encryption only applies to the opentofu engine")))
+	})
+})
@@ -0,0 +1,201 @@
+package terraform
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// Will be mounted in the runner container with the SSH keys, ssh_config and known_hosts
+	// of the workflow/run, ssh is pointed to them by GIT_SSH_COMMAND
+	gitSSHConfigVolumeName string = "git-ssh-config"
+	gitSSHConfigMountPath  string = "/terraform/ssh"
+
+	sshConfigFileName     string = "config"
+	sshKnownHostsFileName string = "known_hosts"
+
+	// the SSH files must only be readable by their owner, ssh refuses a key of its user readable by others,
+	// a runner running as another user than root reads them through the fsGroup of the pod
+	sshFileMode      int32 = 0400
+	sshGroupFileMode int32 = 0440
+)
+
+// hasGitSSHConfig evaluates if the workflow/run has SSH keys, an ssh_config or a known_hosts file
+// in addition to the `id_rsa` key
+func (t *TerraformManipulator) hasGitSSHConfig() bool {
+	key := t.Spec.GitSSHKey
+
+	return key != nil && (len(key.Keys) > 0 || key.ConfigFrom != nil || key.KnownHostsFrom != nil)
+}
+
+// getGitSSHConfigVolume returns a projected volume holding the SSH keys, the ssh_config and the known_hosts of the workflow/run
+func (t *TerraformManipulator) getGitSSHConfigVolume() corev1.Volume {
+	key := t.Spec.GitSSHKey
+	sources := []corev1.VolumeProjection{}
+
+	for _, k := range key.Keys {
+		if k.SecretKeyRef == nil {
+			continue
+		}
+
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: k.SecretKeyRef.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: k.SecretKeyRef.Key, Path: k.Name}},
+				Optional:             k.SecretKeyRef.Optional,
+			},
+		})
+	}
+
+	if key.ConfigFrom != nil {
+		sources = append(sources, getKeyProjection(key.ConfigFrom.ConfigMapKeyRef, key.ConfigFrom.SecretKeyRef, sshConfigFileName)...)
+	}
+
+	if key.KnownHostsFrom != nil {
+		sources = append(sources, getKeyProjection(key.KnownHostsFrom.ConfigMapKeyRef, key.KnownHostsFrom.SecretKeyRef, sshKnownHostsFileName)...)
+	}
+
+	mode := sshFileMode
+
+	return getVolumeSpec(gitSSHConfigVolumeName, corev1.VolumeSource{
+		Projected: &corev1.ProjectedVolumeSource{Sources: sources, DefaultMode: &mode},
+	})
+}
+
+// setGitSSHFileAccess makes the SSH files readable by a runner running as another user than root, the files are
+// readable by the fsGroup of the pod which defaults to the group (or the user) of the runner
+func (t *TerraformManipulator) setGitSSHFileAccess(spec *corev1.PodSpec) {
+	if !t.hasGitSSHConfig() {
+		return
+	}
+
+	user, group, nonRoot := getRunnerUser(spec)
+	if !nonRoot {
+		return
+	}
+
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Name == gitSSHConfigVolumeName && spec.Volumes[i].Projected != nil {
+			mode := sshGroupFileMode
+			spec.Volumes[i].Projected.DefaultMode = &mode
+		}
+	}
+
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+
+	if spec.SecurityContext.FSGroup == nil {
+		if group != nil {
+			spec.SecurityContext.FSGroup = group
+		} else {
+			spec.SecurityContext.FSGroup = user
+		}
+	}
+}
+
+// getRunnerUser returns the user & group the runner container runs as, if set by the security context of the
+// container or of the pod, and evaluates if it doesn't run as root
+func getRunnerUser(spec *corev1.PodSpec) (*int64, *int64, bool) {
+	var user, group *int64
+	var nonRoot *bool
+
+	if sc := spec.SecurityContext; sc != nil {
+		user, group, nonRoot = sc.RunAsUser, sc.RunAsGroup, sc.RunAsNonRoot
+	}
+
+	for _, container := range spec.Containers {
+		sc := container.SecurityContext
+		if container.Name != runnerContainerName || sc == nil {
+			continue
+		}
+
+		if sc.RunAsUser != nil {
+			user = sc.RunAsUser
+		}
+		if sc.RunAsGroup != nil {
+			group = sc.RunAsGroup
+		}
+		if sc.RunAsNonRoot != nil {
+			nonRoot = sc.RunAsNonRoot
+		}
+	}
+
+	if user != nil {
+		return user, group, *user != 0
+	}
+
+	return user, group, nonRoot != nil && *nonRoot
+}
+
+// getGitSSHCommand returns the ssh command used by git, with the keys, ssh_config and known_hosts of the workflow/run
+func (t *TerraformManipulator) getGitSSHCommand() string {
+	key := t.Spec.GitSSHKey
+	args := []string{"ssh"}
+
+	if key.ConfigFrom != nil {
+		args = append(args, "-F", fmt.Sprintf("%s/%s", gitSSHConfigMountPath, sshConfigFileName))
+	}
+
+	if key.KnownHostsFrom != nil {
+		args = append(args, "-o", fmt.Sprintf("UserKnownHostsFile=%s/%s", gitSSHConfigMountPath, sshKnownHostsFileName))
+	}
+
+	for _, k := range key.Keys {
+		args = append(args, "-i", fmt.Sprintf("%s/%s", gitSSHConfigMountPath, k.Name))
+	}
+
+	return strings.Join(args, " ")
+}
+
+// getGitEnvVars returns the environment variables configuring git with the SSH configuration and
+// the HTTPS credentials of the workflow/run, the credentials are passed to git by a credential helper
+// reading them from the environment so they are never written to the git configuration
+func (t *TerraformManipulator) getGitEnvVars() []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+
+	if t.hasGitSSHConfig() {
+		envVars = append(envVars, getEnvVariable("GIT_SSH_COMMAND", t.getGitSSHCommand()))
+	}
+
+	if len(t.Spec.GitHTTPSCredentials) == 0 {
+		return envVars
+	}
+
+	config := [][2]string{}
+
+	for i, c := range t.Spec.GitHTTPSCredentials {
+		usernameVar := fmt.Sprintf("GIT_CREDENTIAL_USERNAME_%d", i)
+		passwordVar := fmt.Sprintf("GIT_CREDENTIAL_PASSWORD_%d", i)
+
+		if c.UsernameFrom != nil {
+			envVars = append(envVars, corev1.EnvVar{Name: usernameVar, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: c.UsernameFrom}})
+		} else {
+			envVars = append(envVars, getEnvVariable(usernameVar, c.Username))
+		}
+
+		envVars = append(envVars, corev1.EnvVar{Name: passwordVar, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: c.PasswordFrom}})
+
+		helper := fmt.Sprintf(`!f() { test "$1" = get && echo "username=${%s}" && echo "password=${%s}"; }; f`, usernameVar, passwordVar)
+		config = append(config, [2]string{fmt.Sprintf("credential.%s.helper", c.URL), helper})
+
+		// the credentials of a repository path are only matched if git compares the paths
+		if u, err := url.Parse(c.URL); err == nil && strings.Trim(u.Path, "/") != "" {
+			config = append(config, [2]string{fmt.Sprintf("credential.%s.useHttpPath", c.URL), "true"})
+		}
+	}
+
+	envVars = append(envVars, getEnvVariable("GIT_TERMINAL_PROMPT", "0"))
+	envVars = append(envVars, getEnvVariable("GIT_CONFIG_COUNT", strconv.Itoa(len(config))))
+
+	for i, c := range config {
+		envVars = append(envVars, getEnvVariable(fmt.Sprintf("GIT_CONFIG_KEY_%d", i), c[0]))
+		envVars = append(envVars, getEnvVariable(fmt.Sprintf("GIT_CONFIG_VALUE_%d", i), c[1]))
+	}
+
+	return envVars
+}
@@ -0,0 +1,112 @@
+package terraform
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+var _ = Describe("Git Authentication", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.Spec.Module.Source = "git::ssh://git@github.com/acme/modules.git"
+	})
+
+	getRunnerEnv := func() []corev1.EnvVar {
+		job, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		return job.Spec.Template.Spec.Containers[0].Env
+	}
+
+	Context("SSH", func() {
+		It("should point ssh to the keys, ssh_config and known_hosts of the run", func() {
+			t.Spec.GitSSHKey = &v1alpha1.GitSSHKey{
+				Keys: []v1alpha1.GitSSHKeyFile{
+					{Name: "id_ed25519", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "github-key"}, Key: "key"}},
+				},
+				ConfigFrom: &v1alpha1.ExtraFileSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ssh"}, Key: "config"},
+				},
+				KnownHostsFrom: &v1alpha1.ExtraFileSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ssh"}, Key: "known_hosts"},
+				},
+			}
+
+			Expect(getRunnerEnv()).To(ContainElement(corev1.EnvVar{
+				Name:  "GIT_SSH_COMMAND",
+				Value: "ssh -F /terraform/ssh/config -o UserKnownHostsFile=/terraform/ssh/known_hosts -i /terraform/ssh/id_ed25519",
+			}))
+
+			volume := t.getGitSSHConfigVolume()
+			Expect(volume.Projected.Sources).To(HaveLen(3))
+			Expect(*volume.Projected.DefaultMode).To(Equal(int32(0400)))
+			Expect(volume.Projected.Sources[0].Secret.Items).To(Equal([]corev1.KeyToPath{{Key: "key", Path: "id_ed25519"}}))
+		})
+
+		It("should make the SSH files readable by a runner running as another user than root", func() {
+			t.Spec.GitSSHKey = &v1alpha1.GitSSHKey{
+				Keys: []v1alpha1.GitSSHKeyFile{
+					{Name: "id_ed25519", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "github-key"}, Key: "key"}},
+				},
+			}
+			t.Spec.Runner = &v1alpha1.Runner{PodTemplate: &apiextensionsv1.JSON{Raw: []byte(
+				`{"spec": {"containers": [{"name": "terraform", "securityContext": {"runAsUser": 1000, "runAsGroup": 3000}}]}}`,
+			)}}
+
+			job, err := t.GetJobSpecForRun(ApplyJob)
+			Expect(err).ToNot(HaveOccurred())
+
+			spec := job.Spec.Template.Spec
+			Expect(*spec.SecurityContext.FSGroup).To(Equal(int64(3000)))
+			Expect(spec.Volumes).To(ContainElement(WithTransform(func(v corev1.Volume) int32 {
+				if v.Name != gitSSHConfigVolumeName {
+					return 0
+				}
+				return *v.Projected.DefaultMode
+			}, Equal(int32(0440)))))
+
+			// the fsGroup of the pod is kept
+			t.Spec.Runner.PodTemplate.Raw = []byte(`{"spec": {"securityContext": {"runAsNonRoot": true, "fsGroup": 2000}}}`)
+
+			job, err = t.GetJobSpecForRun(ApplyJob)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*job.Spec.Template.Spec.SecurityContext.FSGroup).To(Equal(int64(2000)))
+		})
+
+		It("should keep the id_rsa key as is", func() {
+			t.Spec.GitSSHKey = &v1alpha1.GitSSHKey{ValueFrom: &corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "git-ssh-key"}}}
+
+			Expect(t.hasGitSSHConfig()).To(BeFalse())
+			for _, env := range getRunnerEnv() {
+				Expect(env.Name).ToNot(Equal("GIT_SSH_COMMAND"))
+			}
+		})
+	})
+
+	Context("HTTPS", func() {
+		It("should pass the credentials to git with a credential helper", func() {
+			password := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "gitlab"}, Key: "token"}
+
+			t.Spec.GitHTTPSCredentials = []v1alpha1.GitHTTPSCredential{
+				{URL: "https://gitlab.example.com/platform", Username: "oauth2", PasswordFrom: password},
+			}
+
+			Expect(getRunnerEnv()).To(ContainElements(
+				corev1.EnvVar{Name: "GIT_CREDENTIAL_USERNAME_0", Value: "oauth2"},
+				corev1.EnvVar{Name: "GIT_CREDENTIAL_PASSWORD_0", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: password}},
+				corev1.EnvVar{Name: "GIT_CONFIG_COUNT", Value: "2"},
+				corev1.EnvVar{Name: "GIT_CONFIG_KEY_0", Value: "credential.https://gitlab.example.com/platform.helper"},
+				corev1.EnvVar{
+					Name:  "GIT_CONFIG_VALUE_0",
+					Value: `!f() { test "$1" = get && echo "username=${GIT_CREDENTIAL_USERNAME_0}" && echo "password=${GIT_CREDENTIAL_PASSWORD_0}"; }; f`,
+				},
+				corev1.EnvVar{Name: "GIT_CONFIG_KEY_1", Value: "credential.https://gitlab.example.com/platform.useHttpPath"},
+				corev1.EnvVar{Name: "GIT_CONFIG_VALUE_1", Value: "true"},
+			))
+		})
+	})
+})
@@ -0,0 +1,94 @@
+package terraform
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+)
+
+// checkHCLBlocks checks that the HCL configurations written by the users (e.g. backend, providers) only hold blocks
+// of the given types (of any type if none is given), so they can't break out of the file they're written to
+func checkHCLBlocks(src string, blockTypes ...string) error {
+	file, diags := hclsyntax.ParseConfig([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return getHCLError(diags)
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return fmt.Errorf("unexpected HCL body")
+	}
+
+	// the attributes are reported in the order they are written
+	attributes := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attributes = append(attributes, attr)
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].SrcRange.Start.Byte < attributes[j].SrcRange.Start.Byte
+	})
+
+	if len(attributes) > 0 {
+		return fmt.Errorf("unexpected '%s' on line %d, expected a block", attributes[0].Name, attributes[0].SrcRange.Start.Line)
+	}
+
+	if len(blockTypes) == 0 {
+		return nil
+	}
+
+	for _, block := range body.Blocks {
+		if !slices.Contains(blockTypes, block.Type) {
+			return fmt.Errorf("unexpected '%s' on line %d, only %s blocks are allowed", block.Type, block.TypeRange.Start.Line, strings.Join(blockTypes, ", "))
+		}
+	}
+
+	return nil
+}
+
+// checkHCLExpression checks that the HCL expression is complete and doesn't continue past its end
+func checkHCLExpression(src string) error {
+	_, diags := hclsyntax.ParseExpression([]byte(src), "", hcl.InitialPos)
+
+	return getHCLError(diags)
+}
+
+// checkTerraformFile checks that a file of the Terraform project can be parsed, as JSON if it has a .json
+// extension (e.g. main.tf.json) and as HCL otherwise
+func checkTerraformFile(name string, content string) error {
+	var diags hcl.Diagnostics
+
+	if strings.HasSuffix(name, ".json") {
+		var file *hcl.File
+
+		// the root of a Terraform JSON file is an object
+		if file, diags = hcljson.Parse([]byte(content), name); !diags.HasErrors() {
+			_, diags = file.Body.JustAttributes()
+		}
+	} else {
+		_, diags = hclsyntax.ParseConfig([]byte(content), name, hcl.InitialPos)
+	}
+
+	return getHCLError(diags)
+}
+
+// getHCLError returns the first error of the HCL diagnostics with its line, nil if there is none
+func getHCLError(diags hcl.Diagnostics) error {
+	for _, d := range diags {
+		if d.Severity != hcl.DiagError {
+			continue
+		}
+
+		if d.Subject == nil {
+			return fmt.Errorf("%s: %s", d.Summary, d.Detail)
+		}
+
+		return fmt.Errorf("%s on line %d: %s", d.Summary, d.Subject.Start.Line, d.Detail)
+	}
+
+	return nil
+}
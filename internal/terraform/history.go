@@ -0,0 +1,86 @@
+package terraform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+)
+
+// defaultHistoryLimit is the number of runs kept in the status history when no limit is set
+const defaultHistoryLimit int32 = 10
+
+// addRunToHistory records the current workflow/run at the top of the run history
+// and drops the oldest runs beyond the history limit
+func (t *TerraformManipulator) addRunToHistory(trigger v1alpha1.RunTrigger) {
+	run := v1alpha1.PreviousRunStatus{
+		RunID:       t.Status.RunID,
+		Trigger:     trigger,
+		Generation:  t.Generation,
+		StartedTime: time.Now().Format(time.UnixDate),
+		ConfigHash:  t.getConfigHash(),
+	}
+
+	t.Status.History = append([]v1alpha1.PreviousRunStatus{run}, t.Status.History...)
+
+	limit := t.Spec.HistoryLimit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	if len(t.Status.History) > int(limit) {
+		t.Status.History = t.Status.History[:limit]
+	}
+}
+
+// SetRunHistoryStatus records the status of the current workflow/run in the run history,
+// waiting for a dependency happens before the run is submitted so it isn't recorded. The destroy
+// of a workflow/run being deleted is recorded as a run of its own, so it doesn't overwrite the
+// status of the run it destroys
+func (t *TerraformManipulator) SetRunHistoryStatus(status v1alpha1.TerraformRunStatus) {
+	if status == v1alpha1.RunWaitingForDependency {
+		return
+	}
+
+	if len(t.Status.History) == 0 || t.Status.History[0].RunID != t.Status.RunID {
+		return
+	}
+
+	if t.DeletionTimestamp != nil && t.Status.History[0].Trigger != v1alpha1.TriggerDeletion {
+		t.addRunToHistory(v1alpha1.TriggerDeletion)
+	}
+
+	run := &t.Status.History[0]
+	run.Status = status
+	run.CompletionTime = ""
+
+	// a failed destroy is retried by deleting its job, it's then running again
+	if status == v1alpha1.RunCompleted || status == v1alpha1.RunFailed {
+		run.CompletionTime = time.Now().Format(time.UnixDate)
+	}
+}
+
+// getConfigHash returns the SHA-256 hash of the Terraform project files of the workflow/run,
+// or an empty string if the project can't be generated
+func (t *TerraformManipulator) getConfigHash() string {
+	files, err := t.getTerraformProjectFiles()
+	if err != nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s\n%s\n", name, files[name])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
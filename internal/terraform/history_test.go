@@ -0,0 +1,84 @@
+package terraform
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Run History", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		t = &TerraformManipulator{Terraform: &v1alpha1.Terraform{
+			ObjectMeta: metav1.ObjectMeta{Name: "terraform-workflow", Namespace: "default", Generation: 1},
+			Spec: v1alpha1.TerraformSpec{
+				TerraformVersion: "1.0.2",
+				Module:           &v1alpha1.Module{Source: "IbraheemAlSaady/test/module"},
+				HistoryLimit:     2,
+			},
+		}}
+	})
+
+	Context("Submitting runs", func() {
+		It("should record the runs, the current one first, up to the history limit", func() {
+			for i := 0; i < 3; i++ {
+				t.setRunID()
+				t.addRunToHistory(v1alpha1.TriggerUpdated)
+			}
+
+			Expect(t.Status.History).To(HaveLen(2))
+			Expect(t.Status.History[0].RunID).To(Equal(t.Status.RunID))
+			Expect(t.Status.History[1].RunID).To(Equal(t.Status.PreviousRunID))
+			Expect(t.Status.History[0].ConfigHash).To(HaveLen(64))
+			Expect(t.Status.History[0].ConfigHash).To(Equal(t.Status.History[1].ConfigHash))
+		})
+	})
+
+	Context("Completing a run", func() {
+		It("should record the outcome of the current run only", func() {
+			t.setRunID()
+			t.addRunToHistory(v1alpha1.TriggerCreated)
+
+			t.SetRunHistoryStatus(v1alpha1.RunStarted)
+			t.SetRunHistoryStatus(v1alpha1.RunCompleted)
+
+			Expect(t.Status.History[0].Status).To(Equal(v1alpha1.RunCompleted))
+			Expect(t.Status.History[0].Trigger).To(Equal(v1alpha1.TriggerCreated))
+			Expect(t.Status.History[0].Generation).To(Equal(int64(1)))
+			Expect(t.Status.History[0].CompletionTime).ToNot(BeEmpty())
+
+			t.SetRunHistoryStatus(v1alpha1.RunWaitingForDependency)
+			Expect(t.Status.History[0].Status).To(Equal(v1alpha1.RunCompleted))
+		})
+	})
+
+	Context("Destroying a deleted run", func() {
+		It("should record the destroy as a run of its own", func() {
+			t.setRunID()
+			t.addRunToHistory(v1alpha1.TriggerCreated)
+			t.SetRunHistoryStatus(v1alpha1.RunCompleted)
+
+			t.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+			t.SetRunHistoryStatus(v1alpha1.RunDeleting)
+			t.SetRunHistoryStatus(v1alpha1.RunFailed)
+
+			Expect(t.Status.History).To(HaveLen(2))
+			Expect(t.Status.History[0].Trigger).To(Equal(v1alpha1.TriggerDeletion))
+			Expect(t.Status.History[0].RunID).To(Equal(t.Status.RunID))
+			Expect(t.Status.History[0].Status).To(Equal(v1alpha1.RunFailed))
+			Expect(t.Status.History[1].Status).To(Equal(v1alpha1.RunCompleted))
+
+			// the destroy is retried
+			t.SetRunHistoryStatus(v1alpha1.RunDeleting)
+
+			Expect(t.Status.History).To(HaveLen(2))
+			Expect(t.Status.History[0].Status).To(Equal(v1alpha1.RunDeleting))
+			Expect(t.Status.History[0].CompletionTime).To(BeEmpty())
+		})
+	})
+})
@@ -2,7 +2,9 @@ package terraform
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/rinswind/terraform-operator/api/v1alpha1"
 	"github.com/rinswind/terraform-operator/internal/utils"
@@ -26,21 +28,77 @@ const (
 	// Will be mounted in the init-container so it can bootstrap tfProject
 	moduleSourceMountPath string = "/terraform/modules"
 
+	// Will be mounted in the init-container so it can unpack the local modules in tfProject,
+	// a volume is mounted for each local module in a subdirectory named after its module call
+	moduleSourcesVolumePrefix string = "module-source"
+	moduleSourcesMountPath    string = "/terraform/module-sources"
+	// The file names of the parts of a local module tarball within its volume, hidden so they're not copied as module files
+	moduleArchivePartFileName string = ".archive-%d"
+
+	// Will be mounted in the init-container so it can add the extra files from secrets & configmaps to tfProject
+	extraFilesVolumeName string = "extra-files"
+	extraFilesMountPath  string = "/terraform/extra-files"
+
 	gitSSHKeyVolumeName string = "git-ssh"
 	gitSSHKeyMountPath  string = "/root/.ssh"
 
 	knownHostsVolumeName string = "known-hosts"
+
+	// The name of the Terraform Runner container
+	runnerContainerName string = "terraform"
+)
+
+// RunJobType is the type of a Kubernetes Job executed for a workflow/run
+type RunJobType string
+
+// workflow/run job types
+const (
+	// ApplyJob applies (or destroys) the Terraform module
+	ApplyJob RunJobType = "apply"
+	// PlanJob only plans the changes, the run is applied by an ApplyJob once approved
+	PlanJob RunJobType = "plan"
+	// DriftJob runs a refresh-only plan to detect changes made outside of Terraform
+	DriftJob RunJobType = "drift"
+	// DestroyJob destroys the infrastructure of the Terraform object that is being deleted
+	DestroyJob RunJobType = "destroy"
 )
 
-// getJobSpecForRun returns a Kubernetes job spec for the Terraform Runner
-func (t *TerraformManipulator) GetJobSpecForRun() *batchv1.Job {
-	envVars := t.getEnvVariables()
-	volumes := t.getJobVolumes()
-	mounts := t.getJobVolumeMounts()
+// The features of the runner image beyond applying and destroying, declared by the operator configuration
+const (
+	// RunnerFeaturePlanOnly is the support of TERRAFORM_PLAN_ONLY, a runner ignoring it applies the plan jobs
+	RunnerFeaturePlanOnly string = "plan-only"
+	// RunnerFeatureDriftReport is the support of TERRAFORM_REFRESH_ONLY & TERRAFORM_DRIFT_REPORT_PATH,
+	// a runner ignoring them never reports a drift
+	RunnerFeatureDriftReport string = "drift-report"
+)
+
+// GetCurrentJobType returns the type of the job the current workflow/run has to execute
+func (t *TerraformManipulator) GetCurrentJobType() RunJobType {
+	if t.RequiresApproval() && !t.IsApproved() {
+		return PlanJob
+	}
+
+	return ApplyJob
+}
+
+// GetJobSpecForRun returns a Kubernetes job spec for the Terraform Runner, with the pod template of the workflow/run merged in
+func (t *TerraformManipulator) GetJobSpecForRun(jobType RunJobType) (*batchv1.Job, error) {
+	if err := checkRunnerFeatures(jobType); err != nil {
+		return nil, err
+	}
+
+	binaries, err := t.getEngineBinaries()
+	if err != nil {
+		return nil, err
+	}
+
+	envVars := append(t.getEnvVariables(jobType), binaries.getEnvVars()...)
+	volumes := append(t.getJobVolumes(), binaries.getVolumes()...)
+	mounts := append(t.getJobVolumeMounts(), binaries.getVolumeMounts()...)
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      getUniqueResourceName(t.Name, t.Status.RunID),
+			Name:      t.getJobNameForRun(t.Status.RunID, jobType),
 			Namespace: t.Namespace,
 			Labels:    getCommonLabels(t.Name, t.Status.RunID),
 			OwnerReferences: []metav1.OwnerReference{
@@ -53,12 +111,12 @@ func (t *TerraformManipulator) GetJobSpecForRun() *batchv1.Job {
 					Labels: getCommonLabels(t.Name, t.Status.RunID),
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName: runnerRBACName,
+					ServiceAccountName: t.GetServiceAccountName().Name,
 					InitContainers:     t.getInitContainersSpec(),
 					Containers: []corev1.Container{
 						{
-							Name:            "terraform",
-							Image:           getTerraformRunnerDockerImage(),
+							Name:            runnerContainerName,
+							Image:           binaries.getImage(),
 							VolumeMounts:    mounts,
 							Env:             envVars,
 							ImagePullPolicy: corev1.PullIfNotPresent,
@@ -72,8 +130,27 @@ func (t *TerraformManipulator) GetJobSpecForRun() *batchv1.Job {
 	}
 
 	job.Spec.BackoffLimit = &t.Spec.RetryLimit
+	job.Spec.ActiveDeadlineSeconds = t.getJobDeadline(jobType)
+
+	if err := t.applyRunnerPodTemplate(&job.Spec.Template); err != nil {
+		return nil, err
+	}
+
+	t.setGitSSHFileAccess(&job.Spec.Template.Spec)
+
+	return job, nil
+}
+
+// getJobNameForRun returns the name of the job of the given type for a workflow/run,
+// drift checks run repeatedly for the same workflow/run so they are also named after the check ID
+func (t *TerraformManipulator) getJobNameForRun(runID string, jobType RunJobType) string {
+	name := getJobName(t.Name, runID, jobType)
+
+	if jobType == DriftJob && t.Status.Drift != nil {
+		name = fmt.Sprintf("%s-%s", name, t.Status.Drift.CheckID)
+	}
 
-	return job
+	return name
 }
 
 // getInitContainersSpec returns the initContainers definition for the workflow/run job
@@ -82,6 +159,14 @@ func (t *TerraformManipulator) getInitContainersSpec() []corev1.Container {
 
 	cpModule := fmt.Sprintf("cp -v %s/* %s", moduleSourceMountPath, tfProjectDirMountPath)
 
+	if t.hasExtraFileRefs() {
+		cpModule = fmt.Sprintf("%s && cp -v %s/* %s", cpModule, extraFilesMountPath, tfProjectDirMountPath)
+	}
+
+	for _, m := range t.getLocalModules() {
+		cpModule = fmt.Sprintf("%s && %s", cpModule, getLocalModuleUnpackCommand(m))
+	}
+
 	commands := []string{
 		"/bin/sh",
 		"-c",
@@ -113,7 +198,7 @@ func getTerraformRunnerDockerImage() string {
 }
 
 // getEnvVariables returns Kubernetes Pod environment variables (corev1.EnvVar) to be passed to the workflow/run job
-func (t *TerraformManipulator) getEnvVariables() []corev1.EnvVar {
+func (t *TerraformManipulator) getEnvVariables(jobType RunJobType) []corev1.EnvVar {
 	vars := []corev1.EnvVar{}
 
 	for _, v := range t.Spec.Variables {
@@ -132,7 +217,7 @@ func (t *TerraformManipulator) getEnvVariables() []corev1.EnvVar {
 		}
 	}
 
-	vars = append(vars, t.getRunnerSpecificEnvVars()...)
+	vars = append(vars, t.getRunnerSpecificEnvVars(jobType)...)
 
 	return vars
 }
@@ -149,12 +234,17 @@ func getEnvVarKey(v v1alpha1.Variable) string {
 }
 
 // getRunnerSpecificEnvVars returns a list of environment variables to add to the Terraform Runner container
-func (t *TerraformManipulator) getRunnerSpecificEnvVars() []corev1.EnvVar {
+func (t *TerraformManipulator) getRunnerSpecificEnvVars(jobType RunJobType) []corev1.EnvVar {
 	envVars := []corev1.EnvVar{}
 
 	// Terraform execution
-	envVars = append(envVars, getEnvVariable("TERRAFORM_VERSION", t.Spec.TerraformVersion))
-	envVars = append(envVars, getEnvVariable("TERRAFORM_DESTROY", strconv.FormatBool(t.Spec.Destroy)))
+	envVars = append(envVars, t.getEngineEnvVars()...)
+	envVars = append(envVars, getEnvVariable("TERRAFORM_DESTROY", strconv.FormatBool(t.Spec.Destroy || jobType == DestroyJob)))
+	envVars = append(envVars, getEnvVariable("TERRAFORM_PLAN_ONLY", strconv.FormatBool(jobType == PlanJob || jobType == DriftJob)))
+	if jobType == DriftJob {
+		envVars = append(envVars, getEnvVariable("TERRAFORM_REFRESH_ONLY", "true"))
+		envVars = append(envVars, getEnvVariable("TERRAFORM_DRIFT_REPORT_PATH", corev1.TerminationMessagePathDefault))
+	}
 	if t.Spec.Workspace != "" {
 		envVars = append(envVars, getEnvVariable("TERRAFORM_WORKSPACE", t.Spec.Workspace))
 	}
@@ -166,6 +256,18 @@ func (t *TerraformManipulator) getRunnerSpecificEnvVars() []corev1.EnvVar {
 		envVars = append(envVars, getEnvVariable("TF_PLUGIN_CACHE_DIR", tfProviderCacheMountPath))
 	}
 
+	// Git authentication
+	envVars = append(envVars, t.getGitEnvVars()...)
+
+	// Terraform CLI configuration
+	envVars = append(envVars, t.getCLIConfigEnvVars()...)
+
+	// Terraform backend
+	envVars = append(envVars, t.getBackendEnvVars()...)
+
+	// Terraform providers
+	envVars = append(envVars, t.getProviderEnvVars()...)
+
 	// Terraform output
 	envVars = append(envVars, getEnvVariable("OUTPUT_SECRET_NAME", t.GetOutputSecretName().Name))
 	envVars = append(envVars, getEnvVariableFromFieldSelector("POD_NAMESPACE", "metadata.namespace"))
@@ -184,6 +286,11 @@ func (t *TerraformManipulator) getJobVolumeMounts() []corev1.VolumeMount {
 
 	mounts = append(mounts, t.getRunnerVolumeMounts()...)
 
+	// the tokens are only mounted in the runner container
+	if len(t.Spec.ServiceAccountTokens) > 0 {
+		mounts = append(mounts, getVolumeMountSpec(serviceAccountTokensVolumeName, serviceAccountTokensMountPath, true))
+	}
+
 	return mounts
 }
 
@@ -198,6 +305,21 @@ func (t *TerraformManipulator) getRunnerVolumeMounts() []corev1.VolumeMount {
 		mounts = append(mounts, getVolumeMountSpec(tfProviderCacheVolumeName, tfProviderCacheMountPath, false))
 	}
 
+	if t.hasExtraFileRefs() {
+		mounts = append(mounts, getVolumeMountSpec(extraFilesVolumeName, extraFilesMountPath, true))
+	}
+
+	for i, m := range t.getLocalModules() {
+		mountPath := fmt.Sprintf("%s/%s", moduleSourcesMountPath, m.name)
+		mounts = append(mounts, getVolumeMountSpec(getLocalModuleVolumeName(i), mountPath, true))
+	}
+
+	mounts = append(mounts, t.getBackendVolumeMounts()...)
+
+	if t.hasGitSSHConfig() {
+		mounts = append(mounts, getVolumeMountSpec(gitSSHConfigVolumeName, gitSSHConfigMountPath, true))
+	}
+
 	if t.Spec.GitSSHKey != nil && t.Spec.GitSSHKey.ValueFrom != nil {
 		sshKeyFileName := "id_rsa"
 		sshKnownHostsFileName := "known_hosts"
@@ -222,6 +344,10 @@ func (t *TerraformManipulator) getJobVolumes() []corev1.Volume {
 
 	volumes = append(volumes, t.getRunnerVolumes()...)
 
+	if len(t.Spec.ServiceAccountTokens) > 0 {
+		volumes = append(volumes, t.getServiceAccountTokensVolume())
+	}
+
 	return volumes
 }
 
@@ -238,6 +364,20 @@ func (t *TerraformManipulator) getRunnerVolumes() []corev1.Volume {
 		volumes = append(volumes, getVolumeSpec(tfProviderCacheVolumeName, *t.Spec.ProvidersCache))
 	}
 
+	if t.hasExtraFileRefs() {
+		volumes = append(volumes, t.getExtraFilesVolume())
+	}
+
+	for i, m := range t.getLocalModules() {
+		volumes = append(volumes, getLocalModuleVolume(i, m.sourceFrom))
+	}
+
+	volumes = append(volumes, t.getBackendVolumes()...)
+
+	if t.hasGitSSHConfig() {
+		volumes = append(volumes, t.getGitSSHConfigVolume())
+	}
+
 	if t.Spec.GitSSHKey != nil && t.Spec.GitSSHKey.ValueFrom != nil {
 		volumes = append(volumes, getVolumeSpec(gitSSHKeyVolumeName, *t.Spec.GitSSHKey.ValueFrom))
 		volumes = append(volumes, getVolumeSpecFromConfigMap(knownHostsVolumeName, utils.Env.KnownHostsConfigMapName))
@@ -245,3 +385,130 @@ func (t *TerraformManipulator) getRunnerVolumes() []corev1.Volume {
 
 	return volumes
 }
+
+// hasExtraFileRefs evaluates if any extra file of the workflow/run is read from a secret or a configmap
+func (t *TerraformManipulator) hasExtraFileRefs() bool {
+	for _, f := range t.Spec.ExtraFiles {
+		if f.ValueFrom != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getExtraFilesVolume returns a projected volume holding the extra files read from secrets & configmaps,
+// each file is named after the extra file it holds
+func (t *TerraformManipulator) getExtraFilesVolume() corev1.Volume {
+	sources := []corev1.VolumeProjection{}
+
+	for _, f := range t.Spec.ExtraFiles {
+		if f.ValueFrom != nil {
+			sources = append(sources, getKeyProjection(f.ValueFrom.ConfigMapKeyRef, f.ValueFrom.SecretKeyRef, f.Name)...)
+		}
+	}
+
+	return getVolumeSpec(extraFilesVolumeName, corev1.VolumeSource{
+		Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+	})
+}
+
+// localModule is a module call of the workflow/run whose files are read from configmaps & secrets
+type localModule struct {
+	name       string
+	sourceFrom *v1alpha1.ModuleSourceFrom
+}
+
+// getLocalModules returns the local modules of the workflow/run, the spec module first and then the named module calls
+func (t *TerraformManipulator) getLocalModules() []localModule {
+	modules := []localModule{}
+
+	if t.Spec.Module != nil && t.Spec.Module.SourceFrom != nil {
+		modules = append(modules, localModule{name: moduleName, sourceFrom: t.Spec.Module.SourceFrom})
+	}
+
+	for _, m := range t.Spec.Modules {
+		if m.SourceFrom != nil {
+			modules = append(modules, localModule{name: m.Name, sourceFrom: m.SourceFrom})
+		}
+	}
+
+	return modules
+}
+
+// getLocalModuleVolumeName returns the name of the volume of the i-th local module
+func getLocalModuleVolumeName(i int) string {
+	return fmt.Sprintf("%s-%d", moduleSourcesVolumePrefix, i)
+}
+
+// getLocalModuleVolume returns a projected volume holding the files of a local module
+// and the parts of its tarball
+func getLocalModuleVolume(i int, sourceFrom *v1alpha1.ModuleSourceFrom) corev1.Volume {
+	sources := []corev1.VolumeProjection{}
+
+	for _, ref := range sourceFrom.ConfigMaps {
+		sources = append(sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: ref},
+		})
+	}
+
+	for _, ref := range sourceFrom.Secrets {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{LocalObjectReference: ref},
+		})
+	}
+
+	for j, part := range sourceFrom.Archive {
+		path := fmt.Sprintf(moduleArchivePartFileName, j)
+		sources = append(sources, getKeyProjection(part.ConfigMapKeyRef, part.SecretKeyRef, path)...)
+	}
+
+	return getVolumeSpec(getLocalModuleVolumeName(i), corev1.VolumeSource{
+		Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+	})
+}
+
+// getLocalModuleUnpackCommand returns the shell command copying the files of a local module and extracting
+// its tarball in the directory of the Terraform project the module is sourced from
+func getLocalModuleUnpackCommand(m localModule) string {
+	src := fmt.Sprintf("%s/%s", moduleSourcesMountPath, m.name)
+	dst := fmt.Sprintf("%s/%s/%s", tfProjectDirMountPath, localModulesDir, m.name)
+
+	commands := []string{fmt.Sprintf("mkdir -p %s", dst)}
+
+	if len(m.sourceFrom.ConfigMaps) > 0 || len(m.sourceFrom.Secrets) > 0 {
+		commands = append(commands, fmt.Sprintf("cp -v %s/* %s", src, dst))
+	}
+
+	if len(m.sourceFrom.Archive) > 0 {
+		parts := []string{}
+		for j := range m.sourceFrom.Archive {
+			parts = append(parts, fmt.Sprintf("%s/"+moduleArchivePartFileName, src, j))
+		}
+
+		commands = append(commands, fmt.Sprintf("cat %s | tar -xzvf - -C %s", strings.Join(parts, " "), dst))
+	}
+
+	return strings.Join(commands, " && ")
+}
+
+// checkRunnerFeatures checks that the runner image supports the features the job relies on, e.g. a plan job
+// needs a runner that only plans when told to, it would apply the workflow/run otherwise
+func checkRunnerFeatures(jobType RunJobType) error {
+	features := []string{}
+	switch jobType {
+	case PlanJob:
+		features = append(features, RunnerFeaturePlanOnly)
+	case DriftJob:
+		features = append(features, RunnerFeaturePlanOnly, RunnerFeatureDriftReport)
+	}
+
+	for _, feature := range features {
+		if !slices.Contains(utils.Env.TerraformRunnerFeatures, feature) {
+			return fmt.Errorf("the %s job needs a runner image supporting the '%s' feature, "+
+				"the runner images of the operator don't declare it in TERRAFORM_RUNNER_FEATURES", jobType, feature)
+		}
+	}
+
+	return nil
+}
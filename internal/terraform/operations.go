@@ -2,10 +2,15 @@ package terraform
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"time"
 
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -16,11 +21,13 @@ import (
 //
 // (RBAC (service account & Role), ConfigMap for the terraform module file,
 // Secret to store the outputs if any, will be empty if no outputs are defined,
-// Job to execute the workflow/run)
-func (t *TerraformManipulator) CreateTerraformRun(ctx context.Context, c client.Client) (*batchv1.Job, error) {
+// Job to execute the workflow/run), the run is recorded in the run history with the given trigger
+func (t *TerraformManipulator) CreateTerraformRun(ctx context.Context, c client.Client, trigger v1alpha1.RunTrigger) (*batchv1.Job, error) {
 	t.setRunID()
+	t.addRunToHistory(trigger)
 
-	if err := t.createRbacConfigIfNotExist(ctx, c); err != nil {
+	// fail fast on engine versions the operator doesn't have the binaries of
+	if _, err := t.getEngineBinaries(); err != nil {
 		return nil, err
 	}
 
@@ -34,7 +41,7 @@ func (t *TerraformManipulator) CreateTerraformRun(ctx context.Context, c client.
 		return nil, err
 	}
 
-	job, err := t.createJobForRun(ctx, c)
+	job, err := t.createJobForRun(ctx, c, t.GetCurrentJobType())
 	if err != nil {
 		return nil, err
 	}
@@ -42,9 +49,81 @@ func (t *TerraformManipulator) CreateTerraformRun(ctx context.Context, c client.
 	return job, nil
 }
 
+// RejectTerraformRun records a workflow/run that can't be started, e.g. its spec is invalid, in the run history
+// so it fails as a run of its own instead of failing the previous run
+func (t *TerraformManipulator) RejectTerraformRun(trigger v1alpha1.RunTrigger) {
+	t.setRunID()
+	t.addRunToHistory(trigger)
+}
+
+// ApproveTerraformRun records the approval of the current (planned) workflow/run
+// and creates the Kubernetes Job that applies it
+func (t *TerraformManipulator) ApproveTerraformRun(ctx context.Context, c client.Client, approver string) (*batchv1.Job, error) {
+	t.Status.Approval = &v1alpha1.Approval{
+		RunID:        t.Status.RunID,
+		ApprovedBy:   approver,
+		ApprovalTime: time.Now().Format(time.UnixDate),
+	}
+
+	return t.createJobForRun(ctx, c, ApplyJob)
+}
+
+// ClearApproval removes the approval annotations of the workflow/run, e.g. an approval of another run
+func (t *TerraformManipulator) ClearApproval(ctx context.Context, c client.Client) error {
+	patch := client.MergeFrom(t.Terraform.DeepCopy())
+
+	delete(t.Annotations, v1alpha1.ApproveRunAnnotation)
+	delete(t.Annotations, v1alpha1.ApproverAnnotation)
+
+	return c.Patch(ctx, t.Terraform, patch)
+}
+
+// CreateDestroyJob creates the Kubernetes Job that destroys the infrastructure of the current
+// workflow/run before the Terraform object is deleted, the ConfigMap of the run is recreated if it's gone
+func (t *TerraformManipulator) CreateDestroyJob(ctx context.Context, c client.Client) (*batchv1.Job, error) {
+	if err := t.ensureConfigMapForModule(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return t.createJobForRun(ctx, c, DestroyJob)
+}
+
+// StopRunJobs deletes the unfinished Kubernetes Jobs (apply, plan, drift checks) of the current workflow/run,
+// so the destroy doesn't run alongside them, it returns true once they are all gone
+func (t *TerraformManipulator) StopRunJobs(ctx context.Context, c client.Client) (bool, error) {
+	jobs := &batchv1.JobList{}
+
+	if err := c.List(ctx, jobs, client.InNamespace(t.ObjectMeta.Namespace), client.MatchingLabels(getCommonLabels(t.ObjectMeta.Name, t.Status.RunID))); err != nil {
+		return false, err
+	}
+
+	stopped := true
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+
+		if isJobConditionTrue(job, batchv1.JobComplete, "") || isJobConditionTrue(job, batchv1.JobFailed, "") {
+			continue
+		}
+
+		// the job is gone once its pods are
+		stopped = false
+
+		if job.DeletionTimestamp != nil {
+			continue
+		}
+
+		if err := c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	return stopped, nil
+}
+
 // DeleteAfterCompletion removes the Kubernetes of the workflow/run once completed
 func (t *TerraformManipulator) DeleteAfterCompletion(ctx context.Context, c client.Client) error {
-	return t.deleteJobByRun(ctx, c, t.Status.RunID)
+	return t.deleteJobByRun(ctx, c, t.Status.RunID, t.GetCurrentJobType())
 }
 
 // CleanupResources cleans up old resources (secrets & configmaps)
@@ -55,11 +134,9 @@ func (t *TerraformManipulator) CleanupResources(ctx context.Context, c client.Cl
 		return nil
 	}
 
-	// delete the older job
-	if err := t.deleteJobByRun(ctx, c, previousRunID); err != nil {
-		if !errors.IsNotFound(err) {
-			return err
-		}
+	// delete the older jobs
+	if err := t.deleteJobsByRun(ctx, c, previousRunID); err != nil {
+		return err
 	}
 
 	// delete the older configmap that holds the module
@@ -73,9 +150,9 @@ func (t *TerraformManipulator) CleanupResources(ctx context.Context, c client.Cl
 }
 
 // getJobForRun returns the Kubernetes Job of a specific workflow/run
-func (t *TerraformManipulator) GetJobForRun(ctx context.Context, c client.Client, runID string) (*batchv1.Job, error) {
+func (t *TerraformManipulator) GetJobForRun(ctx context.Context, c client.Client, runID string, jobType RunJobType) (*batchv1.Job, error) {
 	jobName := types.NamespacedName{
-		Name:      getUniqueResourceName(t.ObjectMeta.Name, runID),
+		Name:      t.getJobNameForRun(runID, jobType),
 		Namespace: t.ObjectMeta.Namespace,
 	}
 
@@ -89,8 +166,15 @@ func (t *TerraformManipulator) GetJobForRun(ctx context.Context, c client.Client
 }
 
 // createJobForRun creates a Kubernetes Job to execute the workflow/run
-func (t *TerraformManipulator) createJobForRun(ctx context.Context, c client.Client) (*batchv1.Job, error) {
-	job := t.GetJobSpecForRun()
+func (t *TerraformManipulator) createJobForRun(ctx context.Context, c client.Client, jobType RunJobType) (*batchv1.Job, error) {
+	if err := t.reconcileRbacConfig(ctx, c); err != nil {
+		return nil, err
+	}
+
+	job, err := t.GetJobSpecForRun(jobType)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := c.Create(ctx, job); err != nil {
 		return nil, err
@@ -100,8 +184,8 @@ func (t *TerraformManipulator) createJobForRun(ctx context.Context, c client.Cli
 }
 
 // deleteJobByRun deletes the Kubernetes Job of the workflow/run
-func (t *TerraformManipulator) deleteJobByRun(ctx context.Context, c client.Client, runID string) error {
-	job, err := t.GetJobForRun(ctx, c, runID)
+func (t *TerraformManipulator) deleteJobByRun(ctx context.Context, c client.Client, runID string, jobType RunJobType) error {
+	job, err := t.GetJobForRun(ctx, c, runID, jobType)
 
 	if err != nil {
 		return err
@@ -114,6 +198,25 @@ func (t *TerraformManipulator) deleteJobByRun(ctx context.Context, c client.Clie
 	return c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground))
 }
 
+// deleteJobsByRun deletes all the Kubernetes Jobs (apply, plan, drift checks) of the workflow/run
+func (t *TerraformManipulator) deleteJobsByRun(ctx context.Context, c client.Client, runID string) error {
+	jobs := &batchv1.JobList{}
+
+	if err := c.List(ctx, jobs, client.InNamespace(t.ObjectMeta.Namespace), client.MatchingLabels(getCommonLabels(t.ObjectMeta.Name, runID))); err != nil {
+		return err
+	}
+
+	for i := range jobs.Items {
+		if err := c.Delete(ctx, &jobs.Items[i], client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // createSecretForOutputs creates a secret to store the the Terraform output of the workflow/run
 func (t *TerraformManipulator) createSecretForOutputs(ctx context.Context, c client.Client) (*corev1.Secret, error) {
 	secretName := t.GetOutputSecretName()
@@ -149,6 +252,21 @@ func (t *TerraformManipulator) createConfigMapForModule(ctx context.Context, c c
 	return configMap, nil
 }
 
+// ensureConfigMapForModule creates the ConfigMap of the current workflow/run unless it exists, e.g. it was
+// deleted while the run was completed
+func (t *TerraformManipulator) ensureConfigMapForModule(ctx context.Context, c client.Client) error {
+	name := types.NamespacedName{Name: getUniqueResourceName(t.ObjectMeta.Name, t.Status.RunID), Namespace: t.ObjectMeta.Namespace}
+
+	err := c.Get(ctx, name, &corev1.ConfigMap{})
+	if !errors.IsNotFound(err) {
+		return err
+	}
+
+	_, err = t.createConfigMapForModule(ctx, c)
+
+	return err
+}
+
 // deleteConfigMapByRun deletes the Kubernetes Job of the workflow/run
 func (t *TerraformManipulator) deleteConfigMapByRun(ctx context.Context, c client.Client, runID string) error {
 	cmName := getUniqueResourceName(t.ObjectMeta.Name, runID)
@@ -167,81 +285,236 @@ func (t *TerraformManipulator) deleteConfigMapByRun(ctx context.Context, c clien
 	return nil
 }
 
-// createRbacConfigIfNotExist validates if RBAC exist for the Terraform Runner and creates it if not exist
-func (t *TerraformManipulator) createRbacConfigIfNotExist(ctx context.Context, c client.Client) error {
-	saExist, err := t.isServiceAccountExist(ctx, c)
-	if err != nil {
+// reconcileRbacConfig creates the RBAC objects of the runner or brings them back to the spec of the workflow/run,
+// the service account of the spec must exist and the RBAC objects the workflow/run doesn't use anymore are removed
+func (t *TerraformManipulator) reconcileRbacConfig(ctx context.Context, c client.Client) error {
+	if err := t.deleteUnusedRbacConfig(ctx, c); err != nil {
 		return err
 	}
 
-	if !saExist {
-		if _, err := t.createServiceAccount(ctx, c); err != nil {
-			return err
+	if err := t.reconcileServiceAccount(ctx, c); err != nil {
+		return err
+	}
+
+	if err := t.reconcileRole(ctx, c); err != nil {
+		return err
+	}
+
+	return t.reconcileRoleBinding(ctx, c)
+}
+
+// reconcileServiceAccount creates the service account of the workflow/run, or adds the workflow/run to its owners
+// and updates the annotations of the generated one, the service account of the spec is managed by the user
+func (t *TerraformManipulator) reconcileServiceAccount(ctx context.Context, c client.Client) error {
+	obj := &corev1.ServiceAccount{}
+
+	err := c.Get(ctx, t.GetServiceAccountName(), obj)
+	if errors.IsNotFound(err) {
+		if t.Spec.ServiceAccountName != "" {
+			return fmt.Errorf("service account '%s' of the runner doesn't exist", t.Spec.ServiceAccountName)
 		}
+
+		return c.Create(ctx, t.GetServiceAccount())
+	}
+	if err != nil {
+		return err
+	}
+
+	if t.Spec.ServiceAccountName != "" {
+		return nil
+	}
+
+	changed := false
+
+	if !t.isOwnerOf(obj) {
+		obj.OwnerReferences = append(obj.OwnerReferences, t.getOwnerReference())
+		changed = true
+	}
+
+	if desired := t.GetServiceAccount(); !maps.Equal(obj.Annotations, desired.Annotations) {
+		obj.Annotations = desired.Annotations
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return c.Update(ctx, obj)
+}
+
+// reconcileRole creates the role of the workflow/run or updates its rules if they drifted
+func (t *TerraformManipulator) reconcileRole(ctx context.Context, c client.Client) error {
+	obj := &rbacv1.Role{}
+	desired := t.GetRole()
+
+	err := c.Get(ctx, t.GetRoleName(), obj)
+	if errors.IsNotFound(err) {
+		return c.Create(ctx, desired)
+	}
+	if err != nil {
+		return err
 	}
 
-	roleBindingExist, err := t.isRoleBindingExist(ctx, c)
+	if equality.Semantic.DeepEqual(obj.Rules, desired.Rules) {
+		return nil
+	}
+
+	obj.Rules = desired.Rules
+
+	return c.Update(ctx, obj)
+}
+
+// reconcileRoleBinding creates the role binding of the workflow/run or updates its subjects if they drifted,
+// the role of a binding can't be changed so a binding to another role is recreated
+func (t *TerraformManipulator) reconcileRoleBinding(ctx context.Context, c client.Client) error {
+	obj := &rbacv1.RoleBinding{}
+	desired := t.GetRoleBinding()
+
+	err := c.Get(ctx, t.GetRoleBindingName(), obj)
+	if errors.IsNotFound(err) {
+		return c.Create(ctx, desired)
+	}
 	if err != nil {
 		return err
 	}
 
-	if !roleBindingExist {
-		if _, err := t.createRoleBinding(ctx, c); err != nil {
+	if obj.RoleRef != desired.RoleRef {
+		if err := c.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
 			return err
 		}
+
+		return c.Create(ctx, desired)
 	}
 
-	return nil
+	if equality.Semantic.DeepEqual(obj.Subjects, desired.Subjects) {
+		return nil
+	}
+
+	obj.Subjects = desired.Subjects
+
+	return c.Update(ctx, obj)
 }
 
-// isServiceAccountExist checks whether the ServiceAccount for the Terraform Runner exist
-func (t *TerraformManipulator) isServiceAccountExist(ctx context.Context, c client.Client) (bool, error) {
-	err := c.Get(ctx, t.GetServiceAccountName(), &corev1.ServiceAccount{})
+// deleteUnusedRbacConfig removes the RBAC objects the workflow/run doesn't use anymore, the service account
+// generated for it, and the service account shared by the former versions of the operator with its binding
+// to the `terraform-runner` cluster role once nothing in the namespace uses them
+func (t *TerraformManipulator) deleteUnusedRbacConfig(ctx context.Context, c client.Client) error {
+	if t.Spec.ServiceAccountName != "" {
+		if err := t.deleteGeneratedServiceAccount(ctx, c); err != nil {
+			return err
+		}
+	}
 
-	if err == nil {
-		return true, nil
+	return t.deleteSharedRbacConfig(ctx, c)
+}
+
+// deleteGeneratedServiceAccount deletes the service account generated for the workflow/run, if any
+func (t *TerraformManipulator) deleteGeneratedServiceAccount(ctx context.Context, c client.Client) error {
+	obj := &corev1.ServiceAccount{}
+
+	if err := c.Get(ctx, types.NamespacedName{Name: t.getRunnerRBACName(), Namespace: t.Namespace}, obj); err != nil {
+		return client.IgnoreNotFound(err)
 	}
 
-	if errors.IsNotFound(err) {
-		return false, nil
+	if !t.isOwnerOf(obj) {
+		return nil
 	}
 
-	return false, err
+	return client.IgnoreNotFound(c.Delete(ctx, obj))
 }
 
-// isRoleBindingExist checks if the RoleBinding for the Terraform Runner exists
-func (t *TerraformManipulator) isRoleBindingExist(ctx context.Context, c client.Client) (bool, error) {
-	err := c.Get(ctx, t.GetRoleBindingName(), &rbacv1.RoleBinding{})
+// deleteSharedRbacConfig deletes the service account shared by the former versions of the operator and its
+// binding to the `terraform-runner` cluster role, once no workflow/run of the namespace runs as the shared
+// service account and no unfinished job of the namespace still does
+func (t *TerraformManipulator) deleteSharedRbacConfig(ctx context.Context, c client.Client) error {
+	name := types.NamespacedName{Name: runnerRBACName, Namespace: t.Namespace}
 
-	if err == nil {
-		return true, nil
+	binding := &rbacv1.RoleBinding{}
+	if err := c.Get(ctx, name, binding); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		binding = nil
+	} else if binding.RoleRef.Kind != "ClusterRole" || binding.RoleRef.Name != runnerRBACName {
+		binding = nil
 	}
 
-	if errors.IsNotFound(err) {
-		return false, nil
+	sa := &corev1.ServiceAccount{}
+	if err := c.Get(ctx, name, sa); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		sa = nil
 	}
 
-	return false, err
-}
+	if binding == nil && sa == nil {
+		return nil
+	}
 
-// createServiceAccount creates a Kubernetes ServiceAccount for the Terraform Runner
-func (t *TerraformManipulator) createServiceAccount(ctx context.Context, c client.Client) (*corev1.ServiceAccount, error) {
-	obj := t.GetServiceAccount()
+	used, err := t.isSharedServiceAccountUsed(ctx, c)
+	if err != nil || used {
+		return err
+	}
 
-	if err := c.Create(ctx, obj); err != nil {
-		return nil, err
+	if binding != nil {
+		if err := c.Delete(ctx, binding); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
 	}
 
-	return obj, nil
+	if sa != nil {
+		if err := c.Delete(ctx, sa); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// createRoleBinding creates a Kubernetes RoleBinding for the Terraform Runner
-func (t *TerraformManipulator) createRoleBinding(ctx context.Context, c client.Client) (*rbacv1.RoleBinding, error) {
-	obj := t.GetRoleBinding()
+// isSharedServiceAccountUsed evaluates if a workflow/run of the namespace runs as the service account shared by
+// the former versions of the operator, or if an unfinished job of the namespace (e.g. started by them) still does
+func (t *TerraformManipulator) isSharedServiceAccountUsed(ctx context.Context, c client.Client) (bool, error) {
+	runs := &v1alpha1.TerraformList{}
 
-	if err := c.Create(ctx, obj); err != nil {
-		return nil, err
+	if err := c.List(ctx, runs, client.InNamespace(t.Namespace)); err != nil {
+		return false, err
 	}
 
-	return obj, nil
+	for _, run := range runs.Items {
+		if run.Spec.ServiceAccountName == runnerRBACName {
+			return true, nil
+		}
+	}
+
+	jobs := &batchv1.JobList{}
+
+	if err := c.List(ctx, jobs, client.InNamespace(t.Namespace)); err != nil {
+		return false, err
+	}
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+
+		if job.Spec.Template.Spec.ServiceAccountName != runnerRBACName {
+			continue
+		}
+
+		if !isJobConditionTrue(job, batchv1.JobComplete, "") && !isJobConditionTrue(job, batchv1.JobFailed, "") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// isOwnerOf evaluates if the workflow/run owns the given object
+func (t *TerraformManipulator) isOwnerOf(obj client.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == t.GetUID() {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,226 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// providerConfigRegex matches the addresses of the provider configurations, `<name>` or `<name>.<alias>`
+var providerConfigRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*(\.[a-zA-Z_][a-zA-Z0-9_-]*)?$`)
+
+// typedProviderBlocks holds the blocks generated from the typed providers of the workflow/run
+type typedProviderBlocks struct {
+	// the required_providers of the terraform block keyed by provider name
+	required map[string]interface{}
+	// the provider blocks keyed by provider name, a provider has a block per alias
+	providers map[string]interface{}
+	// the sensitive variables holding the provider arguments read from secrets
+	variables map[string]interface{}
+}
+
+// getTypedProviderBlocks generates the required providers, the provider blocks and the sensitive variables
+// of the typed providers of the workflow/run
+func (t *TerraformManipulator) getTypedProviderBlocks() (*typedProviderBlocks, error) {
+	blocks := &typedProviderBlocks{
+		required:  map[string]interface{}{},
+		providers: map[string]interface{}{},
+		variables: map[string]interface{}{},
+	}
+
+	configs := map[string]bool{}
+
+	for _, p := range t.Spec.TypedProviders {
+		if err := checkProvider(p); err != nil {
+			return nil, err
+		}
+
+		address := getProviderAddress(p)
+		if configs[address] {
+			return nil, fmt.Errorf("provider '%s' is defined more than once", address)
+		}
+		configs[address] = true
+
+		if err := blocks.addRequirement(p); err != nil {
+			return nil, err
+		}
+
+		config, err := getProviderConfig(p)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range p.ConfigFrom {
+			name := getProviderVariableName(p, a.Name)
+
+			config[a.Name] = fmt.Sprintf("${var.%s}", name)
+			blocks.variables[name] = map[string]interface{}{"sensitive": true}
+		}
+
+		if len(config) > 0 {
+			aliases, _ := blocks.providers[p.Name].([]interface{})
+			blocks.providers[p.Name] = append(aliases, config)
+		}
+	}
+
+	return blocks, nil
+}
+
+// addRequirement adds the source & version of a provider to the required providers, the configurations
+// of a provider can't require different sources or versions
+func (b *typedProviderBlocks) addRequirement(p v1alpha1.Provider) error {
+	if p.Source == "" && p.Version == "" {
+		return nil
+	}
+
+	requirement, ok := b.required[p.Name].(map[string]interface{})
+	if !ok {
+		requirement = map[string]interface{}{}
+		b.required[p.Name] = requirement
+	}
+
+	for key, value := range map[string]string{"source": p.Source, "version": p.Version} {
+		if value == "" {
+			continue
+		}
+
+		if current, ok := requirement[key]; ok && current != value {
+			return fmt.Errorf("provider '%s' requires different %s values", p.Name, key)
+		}
+
+		requirement[key] = value
+	}
+
+	return nil
+}
+
+// checkProvider checks the names of a typed provider, of its alias and of its arguments read from secrets
+func checkProvider(p v1alpha1.Provider) error {
+	if !identifierRegex.MatchString(p.Name) {
+		return fmt.Errorf("provider name '%s' is not a valid provider name", p.Name)
+	}
+
+	if p.Alias != "" && !identifierRegex.MatchString(p.Alias) {
+		return fmt.Errorf("alias '%s' of provider '%s' is not a valid alias", p.Alias, p.Name)
+	}
+
+	arguments := map[string]bool{}
+
+	for _, a := range p.ConfigFrom {
+		if !identifierRegex.MatchString(a.Name) || a.Name == "alias" {
+			return fmt.Errorf("argument '%s' of provider '%s' is not a valid argument name", a.Name, getProviderAddress(p))
+		}
+
+		if arguments[a.Name] {
+			return fmt.Errorf("argument '%s' of provider '%s' is defined more than once", a.Name, getProviderAddress(p))
+		}
+		arguments[a.Name] = true
+	}
+
+	return nil
+}
+
+// getProviderConfig returns the arguments of the provider block of a typed provider, without the ones read from secrets
+func getProviderConfig(p v1alpha1.Provider) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+
+	if p.Config != nil {
+		decoder := json.NewDecoder(bytes.NewReader(p.Config.Raw))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&config); err != nil || config == nil {
+			return nil, fmt.Errorf("config of provider '%s' is not a JSON object", getProviderAddress(p))
+		}
+	}
+
+	if _, ok := config["alias"]; ok {
+		return nil, fmt.Errorf("config of provider '%s' can't set the alias, use the alias field", getProviderAddress(p))
+	}
+
+	for _, a := range p.ConfigFrom {
+		if _, ok := config[a.Name]; ok {
+			return nil, fmt.Errorf("argument '%s' of provider '%s' is set in both config and configFrom", a.Name, getProviderAddress(p))
+		}
+	}
+
+	if p.Alias != "" {
+		config["alias"] = p.Alias
+	}
+
+	return config, nil
+}
+
+// getModuleProviders returns the providers argument of a module block, the module gets all the typed
+// providers with their own names unless it maps them explicitly
+func (t *TerraformManipulator) getModuleProviders(name string, m v1alpha1.Module) (map[string]interface{}, error) {
+	providers := map[string]interface{}{}
+
+	configs := map[string]bool{}
+	for _, p := range t.Spec.TypedProviders {
+		configs[getProviderAddress(p)] = true
+	}
+
+	if len(m.Providers) == 0 {
+		for address := range configs {
+			providers[address] = address
+		}
+
+		return providers, nil
+	}
+
+	for _, p := range m.Providers {
+		if !providerConfigRegex.MatchString(p.Name) {
+			return nil, fmt.Errorf("provider '%s' of module '%s' is not a valid provider configuration name", p.Name, name)
+		}
+
+		if !configs[p.Provider] {
+			return nil, fmt.Errorf("provider '%s' of module '%s' references the unknown provider '%s'", p.Name, name, p.Provider)
+		}
+
+		if _, ok := providers[p.Name]; ok {
+			return nil, fmt.Errorf("provider '%s' of module '%s' is defined more than once", p.Name, name)
+		}
+
+		providers[p.Name] = p.Provider
+	}
+
+	return providers, nil
+}
+
+// getProviderEnvVars returns the environment variables setting the sensitive variables of the provider arguments read from secrets
+func (t *TerraformManipulator) getProviderEnvVars() []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+
+	for _, p := range t.Spec.TypedProviders {
+		for _, a := range p.ConfigFrom {
+			envVars = append(envVars, corev1.EnvVar{
+				Name:      fmt.Sprintf("TF_VAR_%s", getProviderVariableName(p, a.Name)),
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: a.SecretKeyRef.DeepCopy()},
+			})
+		}
+	}
+
+	return envVars
+}
+
+// getProviderAddress returns the address of the configuration of a typed provider, `<name>` or `<name>.<alias>`
+func getProviderAddress(p v1alpha1.Provider) string {
+	if p.Alias == "" {
+		return p.Name
+	}
+
+	return fmt.Sprintf("%s.%s", p.Name, p.Alias)
+}
+
+// getProviderVariableName returns the name of the sensitive variable holding a provider argument read from a secret
+func getProviderVariableName(p v1alpha1.Provider, argument string) string {
+	if p.Alias == "" {
+		return fmt.Sprintf("provider_%s_%s", p.Name, argument)
+	}
+
+	return fmt.Sprintf("provider_%s_%s_%s", p.Name, p.Alias, argument)
+}
@@ -0,0 +1,96 @@
+package terraform
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+var _ = Describe("Typed Providers", func() {
+	var t *TerraformManipulator
+
+	accessKey := corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "aws"}, Key: "access_key"}
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.Spec.Variables = []v1alpha1.Variable{{Key: "length", Value: "16"}}
+		t.Spec.TypedProviders = []v1alpha1.Provider{
+			{Name: "aws", Source: "hashicorp/aws", Version: "~> 5.0", Config: &apiextensionsv1.JSON{Raw: []byte(`{"region": "eu-west-1"}`)}},
+			{
+				Name:       "aws",
+				Alias:      "us_east_1",
+				Config:     &apiextensionsv1.JSON{Raw: []byte(`{"region": "us-east-1", "max_retries": 25}`)},
+				ConfigFrom: []v1alpha1.ProviderArgumentSource{{Name: "access_key", SecretKeyRef: accessKey}},
+			},
+		}
+	})
+
+	It("should generate the required providers and the provider blocks and pass them to the module", func() {
+		main, err := t.getTerraformMainFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(main).To(MatchJSON(`{
+			"terraform": {
+				"required_version": "~> 1.0.2",
+				"required_providers": {"aws": {"source": "hashicorp/aws", "version": "~> 5.0"}}
+			},
+			"provider": {
+				"aws": [
+					{"region": "eu-west-1"},
+					{"region": "us-east-1", "max_retries": 25, "alias": "us_east_1", "access_key": "${var.provider_aws_us_east_1_access_key}"}
+				]
+			},
+			"variable": {
+				"length": {},
+				"provider_aws_us_east_1_access_key": {"sensitive": true}
+			},
+			"module": {
+				"operator": {
+					"source": "IbraheemAlSaady/test/module",
+					"providers": {"aws": "aws", "aws.us_east_1": "aws.us_east_1"},
+					"length": "${var.length}"
+				}
+			}
+		}`))
+
+		Expect(t.getProviderEnvVars()).To(Equal([]corev1.EnvVar{
+			{Name: "TF_VAR_provider_aws_us_east_1_access_key", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &accessKey}},
+		}))
+	})
+
+	It("should pass the mapped provider configurations to the module", func() {
+		t.Spec.Module.Providers = []v1alpha1.ModuleProvider{{Name: "aws.primary", Provider: "aws.us_east_1"}}
+
+		main, err := t.getTerraformMainFile()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(main)).To(ContainSubstring(`"providers": {
+        "aws.primary": "aws.us_east_1"
+      }`))
+
+		t.Spec.Module.Providers = []v1alpha1.ModuleProvider{{Name: "aws.primary", Provider: "aws.eu_west_1"}}
+
+		_, err = t.getTerraformMainFile()
+		Expect(err).To(MatchError(ContainSubstring("references the unknown provider 'aws.eu_west_1'")))
+	})
+
+	It("should reject provider configurations that can't be generated", func() {
+		t.Spec.TypedProviders[0].Config = &apiextensionsv1.JSON{Raw: []byte(`{"alias": "eu_west_1"}`)}
+
+		_, err := t.getTerraformMainFile()
+		Expect(err).To(MatchError(ContainSubstring("can't set the alias")))
+
+		t.Spec.TypedProviders[0].Config = nil
+		t.Spec.TypedProviders[1].Version = "~> 4.0"
+
+		_, err = t.getTerraformMainFile()
+		Expect(err).To(MatchError(ContainSubstring("requires different version values")))
+	})
+
+	It("should not be combined with the providers configuration", func() {
+		t.Spec.ProvidersConfig = `provider "aws" {}`
+
+		_, err := t.getTerraformProjectFiles()
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -1,6 +1,9 @@
 package terraform
 
 import (
+	"fmt"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -8,15 +11,28 @@ import (
 )
 
 const (
-	// runnerRBACName is the RBAC name that will be used in the role and service account creation
-	// if they're not found
+	// runnerRBACName prefixes the service account, role & role binding generated for a workflow/run, it is also
+	// the name of the service account shared by the workflows/runs of a namespace in the former versions
 	runnerRBACName string = "terraform-runner"
+
+	// Will be mounted in the runner container with the projected service account tokens
+	serviceAccountTokensVolumeName string = "sa-tokens"
+	serviceAccountTokensMountPath  string = "/var/run/secrets/tokens"
 )
 
+// GetServiceAccountName returns the name of the service account the jobs of the workflow/run run as, the service account
+// of the spec or the service account generated for the workflow/run
 func (t *TerraformManipulator) GetServiceAccountName() types.NamespacedName {
-	return types.NamespacedName{Name: runnerRBACName, Namespace: t.ObjectMeta.Namespace}
+	name := t.getRunnerRBACName()
+
+	if t.Spec.ServiceAccountName != "" {
+		name = t.Spec.ServiceAccountName
+	}
+
+	return types.NamespacedName{Name: name, Namespace: t.ObjectMeta.Namespace}
 }
 
+// GetServiceAccount returns the service account generated for the workflow/run jobs, it is owned by the workflow/run
 func (t *TerraformManipulator) GetServiceAccount() *corev1.ServiceAccount {
 	name := t.GetServiceAccountName()
 
@@ -24,37 +40,184 @@ func (t *TerraformManipulator) GetServiceAccount() *corev1.ServiceAccount {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name.Name,
 			Namespace: name.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				t.getOwnerReference(),
+			},
 		},
 	}
 
+	if t.Spec.ServiceAccount != nil {
+		obj.Annotations = t.Spec.ServiceAccount.Annotations
+	}
+
 	return obj
 }
 
+// GetRoleName returns the name of the role of the workflow/run
+func (t *TerraformManipulator) GetRoleName() types.NamespacedName {
+	return types.NamespacedName{Name: t.getRunnerRBACName(), Namespace: t.ObjectMeta.Namespace}
+}
+
+// GetRole returns the role granting the runner access to the secrets of the workflow/run only, its output
+// secret and the state of its Kubernetes backend, the role is owned by the workflow/run
+func (t *TerraformManipulator) GetRole() *rbacv1.Role {
+	name := t.GetRoleName()
+
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name.Name,
+			Namespace: name.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				t.getOwnerReference(),
+			},
+		},
+		Rules: t.getRunnerPolicyRules(),
+	}
+}
+
+// GetRoleBindingName returns the name of the role binding of the workflow/run, named after its role
 func (t *TerraformManipulator) GetRoleBindingName() types.NamespacedName {
-	return types.NamespacedName{Name: runnerRBACName, Namespace: t.ObjectMeta.Namespace}
+	return t.GetRoleName()
 }
 
+// GetRoleBinding returns the role binding granting the role of the workflow/run to its service account,
+// the role binding is owned by the workflow/run
 func (t *TerraformManipulator) GetRoleBinding() *rbacv1.RoleBinding {
 	name := t.GetRoleBindingName()
 
-	obj := &rbacv1.RoleBinding{
+	return &rbacv1.RoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name.Name,
 			Namespace: name.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				t.getOwnerReference(),
+			},
 		},
 		RoleRef: rbacv1.RoleRef{
-			Kind:     "ClusterRole",
-			Name:     runnerRBACName,
-			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     t.GetRoleName().Name,
+			APIGroup: rbacv1.GroupName,
 		},
 		Subjects: []rbacv1.Subject{
 			{
-				Kind:      "ServiceAccount",
-				Name:      runnerRBACName,
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      t.GetServiceAccountName().Name,
 				Namespace: t.ObjectMeta.Namespace,
 			},
 		},
 	}
+}
 
-	return obj
+// getRunnerPolicyRules returns the permissions of the runner, the secrets and leases of the Kubernetes backend
+// can't be created by name, so this verb is only granted if the state is kept in the namespace. Listing the
+// secrets returns their data, so it is only granted to select a non-default workspace if the workflow/run allows it
+func (t *TerraformManipulator) getRunnerPolicyRules() []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{t.GetOutputSecretName().Name},
+			Verbs:         []string{"get", "update", "patch"},
+		},
+	}
+
+	suffix, ok := t.getStateSecretSuffix()
+	if !ok {
+		return rules
+	}
+
+	// terraform init selects the default workspace before the workspace of the workflow/run
+	workspaces := []string{defaultWorkspace}
+	if t.Spec.Workspace != "" && t.Spec.Workspace != defaultWorkspace {
+		workspaces = append(workspaces, t.Spec.Workspace)
+	}
+
+	secrets := []string{}
+	leases := []string{}
+	for _, workspace := range workspaces {
+		secrets = append(secrets, fmt.Sprintf("tfstate-%s-%s", workspace, suffix))
+		leases = append(leases, fmt.Sprintf("lock-tfstate-%s-%s", workspace, suffix))
+	}
+
+	verbs := []string{"create"}
+	if t.Spec.AllowSecretList && len(workspaces) > 1 {
+		verbs = append(verbs, "list")
+	}
+
+	return append(rules,
+		rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: secrets,
+			Verbs:         []string{"get", "update", "patch"},
+		},
+		rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     verbs,
+		},
+		rbacv1.PolicyRule{
+			APIGroups:     []string{"coordination.k8s.io"},
+			Resources:     []string{"leases"},
+			ResourceNames: leases,
+			Verbs:         []string{"get", "update"},
+		},
+		rbacv1.PolicyRule{
+			APIGroups: []string{"coordination.k8s.io"},
+			Resources: []string{"leases"},
+			Verbs:     []string{"create"},
+		},
+	)
+}
+
+// getStateSecretSuffix returns the secret suffix of the Kubernetes backend of the workflow/run if its state is
+// kept in the namespace of the workflow/run with the in-cluster configuration, i.e. with the service account
+func (t *TerraformManipulator) getStateSecretSuffix() (string, bool) {
+	backend := t.Spec.TypedBackend
+
+	if backend == nil {
+		return t.Name, t.Spec.Backend == "" || t.Spec.Backend == t.getDefaultBackend()
+	}
+
+	if backend.Type != v1alpha1.BackendKubernetes {
+		return "", false
+	}
+
+	if namespace, ok := backend.Settings["namespace"]; ok && namespace != t.Namespace {
+		return "", false
+	}
+
+	if inCluster, ok := backend.Settings["in_cluster_config"]; ok && inCluster != "true" {
+		return "", false
+	}
+
+	if suffix, ok := backend.Settings["secret_suffix"]; ok {
+		return suffix, true
+	}
+
+	return t.Name, true
+}
+
+// getRunnerRBACName returns the name of the service account, role & role binding generated for the workflow/run
+func (t *TerraformManipulator) getRunnerRBACName() string {
+	return fmt.Sprintf("%s-%s", runnerRBACName, truncateResourceName(t.Name, 220))
+}
+
+// getServiceAccountTokensVolume returns the projected volume of the service account tokens of the workflow/run
+func (t *TerraformManipulator) getServiceAccountTokensVolume() corev1.Volume {
+	sources := []corev1.VolumeProjection{}
+
+	for _, token := range t.Spec.ServiceAccountTokens {
+		sources = append(sources, corev1.VolumeProjection{
+			ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+				Audience:          token.Audience,
+				ExpirationSeconds: token.ExpirationSeconds,
+				Path:              token.Path,
+			},
+		})
+	}
+
+	return getVolumeSpec(serviceAccountTokensVolumeName, corev1.VolumeSource{
+		Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+	})
 }
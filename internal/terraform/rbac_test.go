@@ -0,0 +1,272 @@
+package terraform
+
+import (
+	"context"
+	"slices"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Runner Service Account", func() {
+	var t *TerraformManipulator
+	var c client.Client
+
+	ctx := context.Background()
+	generatedName := types.NamespacedName{Namespace: "default", Name: "terraform-runner-network"}
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.Kind = "Terraform"
+		t.Name = "network"
+		t.UID = "network-uid"
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(rbacv1.AddToScheme(scheme)).To(Succeed())
+		Expect(batchv1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		c = fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&batchv1.Job{}).Build()
+	})
+
+	It("should run as the service account generated for the run by default", func() {
+		legacy := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: runnerRBACName},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: runnerRBACName, APIGroup: rbacv1.GroupName},
+		}
+		Expect(c.Create(ctx, legacy)).To(Succeed())
+
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+
+		sa := &corev1.ServiceAccount{}
+		Expect(c.Get(ctx, generatedName, sa)).To(Succeed())
+		Expect(t.isOwnerOf(sa)).To(BeTrue())
+		Expect(sa.Annotations).To(BeEmpty())
+
+		binding := &rbacv1.RoleBinding{}
+		Expect(c.Get(ctx, generatedName, binding)).To(Succeed())
+		Expect(binding.RoleRef).To(Equal(rbacv1.RoleRef{Kind: "Role", Name: generatedName.Name, APIGroup: rbacv1.GroupName}))
+		Expect(binding.Subjects[0].Name).To(Equal(generatedName.Name))
+
+		// the binding to the cluster role is replaced by the role of the run
+		Expect(errors.IsNotFound(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: runnerRBACName}, binding))).To(BeTrue())
+
+		job, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal(generatedName.Name))
+	})
+
+	It("should only grant the run access to its output secret and the state of its backend", func() {
+		t.Spec.Workspace = "staging"
+
+		role := &rbacv1.Role{}
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+		Expect(c.Get(ctx, generatedName, role)).To(Succeed())
+		Expect(t.isOwnerOf(role)).To(BeTrue())
+
+		Expect(role.Rules).To(HaveLen(5))
+		Expect(role.Rules[0].ResourceNames).To(Equal([]string{"network-outputs"}))
+		Expect(role.Rules[1].ResourceNames).To(Equal([]string{"tfstate-default-network", "tfstate-staging-network"}))
+		Expect(role.Rules[3].ResourceNames).To(Equal([]string{"lock-tfstate-default-network", "lock-tfstate-staging-network"}))
+
+		t.Spec.TypedBackend = &v1alpha1.TypedBackend{
+			Type:     v1alpha1.BackendS3,
+			Settings: map[string]string{"bucket": "state", "key": "network.tfstate", "region": "eu-west-1"},
+		}
+		Expect(t.getRunnerPolicyRules()).To(HaveLen(1))
+
+		t.Spec.TypedBackend = &v1alpha1.TypedBackend{
+			Type:     v1alpha1.BackendKubernetes,
+			Settings: map[string]string{"secret_suffix": "shared-network"},
+		}
+		Expect(t.getRunnerPolicyRules()[1].ResourceNames).To(ContainElement("tfstate-staging-shared-network"))
+
+		t.Spec.TypedBackend.Settings["namespace"] = "states"
+		Expect(t.getRunnerPolicyRules()).To(HaveLen(1))
+	})
+
+	It("should only grant unrestricted read access to the secrets to select a workspace the run allows listing for", func() {
+		// the secrets readable without being named, listing or watching them returns their data
+		unrestricted := func(rules []rbacv1.PolicyRule) []string {
+			verbs := []string{}
+			for _, rule := range rules {
+				if !slices.Contains(rule.Resources, "secrets") || len(rule.ResourceNames) > 0 {
+					continue
+				}
+				for _, verb := range rule.Verbs {
+					if verb == "get" || verb == "list" || verb == "watch" || verb == "*" {
+						verbs = append(verbs, verb)
+					}
+				}
+			}
+			return verbs
+		}
+
+		Expect(unrestricted(t.getRunnerPolicyRules())).To(BeEmpty())
+
+		// the default workspace is found without listing the secrets
+		t.Spec.AllowSecretList = true
+		Expect(unrestricted(t.getRunnerPolicyRules())).To(BeEmpty())
+
+		t.Spec.Workspace = "dev"
+		Expect(unrestricted(t.getRunnerPolicyRules())).To(Equal([]string{"list"}))
+
+		t.Spec.AllowSecretList = false
+		Expect(unrestricted(t.getRunnerPolicyRules())).To(BeEmpty())
+	})
+
+	It("should reconcile the drift of the role and the role binding", func() {
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+
+		role := &rbacv1.Role{}
+		Expect(c.Get(ctx, generatedName, role)).To(Succeed())
+		role.Rules = append(role.Rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"*"}})
+		Expect(c.Update(ctx, role)).To(Succeed())
+
+		binding := &rbacv1.RoleBinding{}
+		Expect(c.Get(ctx, generatedName, binding)).To(Succeed())
+		binding.Subjects = append(binding.Subjects, rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "default", Namespace: "default"})
+		Expect(c.Update(ctx, binding)).To(Succeed())
+
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+
+		Expect(c.Get(ctx, generatedName, role)).To(Succeed())
+		Expect(role.Rules).To(Equal(t.GetRole().Rules))
+
+		Expect(c.Get(ctx, generatedName, binding)).To(Succeed())
+		Expect(binding.Subjects).To(Equal(t.GetRoleBinding().Subjects))
+	})
+
+	It("should generate a service account owned by the run and keep its annotations up to date", func() {
+		t.Spec.ServiceAccount = &v1alpha1.RunnerServiceAccount{
+			Annotations: map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/network"},
+		}
+
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+
+		sa := &corev1.ServiceAccount{}
+		Expect(c.Get(ctx, generatedName, sa)).To(Succeed())
+		Expect(sa.Annotations).To(Equal(t.Spec.ServiceAccount.Annotations))
+		Expect(t.isOwnerOf(sa)).To(BeTrue())
+
+		binding := &rbacv1.RoleBinding{}
+		Expect(c.Get(ctx, generatedName, binding)).To(Succeed())
+		Expect(binding.RoleRef.Name).To(Equal(generatedName.Name))
+		Expect(binding.Subjects[0].Name).To(Equal(generatedName.Name))
+
+		t.Spec.ServiceAccount.Annotations = map[string]string{"iam.gke.io/gcp-service-account": "network@project.iam.gserviceaccount.com"}
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+
+		Expect(c.Get(ctx, generatedName, sa)).To(Succeed())
+		Expect(sa.Annotations).To(Equal(t.Spec.ServiceAccount.Annotations))
+
+		job, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal(generatedName.Name))
+
+		t.Spec.ServiceAccount = nil
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+
+		Expect(c.Get(ctx, generatedName, sa)).To(Succeed())
+		Expect(sa.Annotations).To(BeEmpty())
+	})
+
+	It("should run as an existing service account bound to the role of the run", func() {
+		t.Spec.ServiceAccountName = "network-deployer"
+
+		Expect(t.reconcileRbacConfig(ctx, c)).To(MatchError(ContainSubstring("'network-deployer' of the runner doesn't exist")))
+
+		Expect(c.Create(ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "network-deployer"}})).To(Succeed())
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+
+		sa := &corev1.ServiceAccount{}
+		Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "network-deployer"}, sa)).To(Succeed())
+		Expect(sa.OwnerReferences).To(BeEmpty())
+
+		binding := &rbacv1.RoleBinding{}
+		Expect(c.Get(ctx, generatedName, binding)).To(Succeed())
+		Expect(binding.Subjects[0].Name).To(Equal("network-deployer"))
+	})
+
+	It("should delete the generated service account once the run uses an existing one", func() {
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+		Expect(c.Get(ctx, generatedName, &corev1.ServiceAccount{})).To(Succeed())
+
+		t.Spec.ServiceAccountName = "network-deployer"
+		Expect(c.Create(ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "network-deployer"}})).To(Succeed())
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+
+		Expect(errors.IsNotFound(c.Get(ctx, generatedName, &corev1.ServiceAccount{}))).To(BeTrue())
+	})
+
+	It("should delete the shared service account of the former versions of the operator once nothing uses it", func() {
+		sharedName := types.NamespacedName{Namespace: "default", Name: runnerRBACName}
+
+		// the former versions created them without owners
+		Expect(c.Create(ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: sharedName.Name, Namespace: sharedName.Namespace}})).To(Succeed())
+		Expect(c.Create(ctx, &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: sharedName.Name, Namespace: sharedName.Namespace},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: runnerRBACName, APIGroup: rbacv1.GroupName},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: runnerRBACName, Namespace: "default"}},
+		})).To(Succeed())
+
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "database-abc123", Namespace: "default"},
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{ServiceAccountName: runnerRBACName}},
+			},
+		}
+		Expect(c.Create(ctx, job)).To(Succeed())
+
+		other := newTestTerraform()
+		other.Name = "database"
+		other.Spec.ServiceAccountName = runnerRBACName
+		Expect(c.Create(ctx, other.Terraform)).To(Succeed())
+
+		// the job of the former versions is still running
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+		Expect(c.Get(ctx, sharedName, &corev1.ServiceAccount{})).To(Succeed())
+		Expect(c.Get(ctx, sharedName, &rbacv1.RoleBinding{})).To(Succeed())
+
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		Expect(c.Status().Update(ctx, job)).To(Succeed())
+
+		// another run still runs as the shared service account
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+		Expect(c.Get(ctx, sharedName, &corev1.ServiceAccount{})).To(Succeed())
+		Expect(c.Get(ctx, sharedName, &rbacv1.RoleBinding{})).To(Succeed())
+
+		Expect(c.Delete(ctx, other.Terraform)).To(Succeed())
+
+		Expect(t.reconcileRbacConfig(ctx, c)).To(Succeed())
+		Expect(errors.IsNotFound(c.Get(ctx, sharedName, &corev1.ServiceAccount{}))).To(BeTrue())
+		Expect(errors.IsNotFound(c.Get(ctx, sharedName, &rbacv1.RoleBinding{}))).To(BeTrue())
+	})
+
+	It("should project the service account tokens in the runner container", func() {
+		expiration := int64(3600)
+		t.Spec.ServiceAccountTokens = []v1alpha1.ServiceAccountToken{{Path: "aws", Audience: "sts.amazonaws.com", ExpirationSeconds: &expiration}}
+
+		job, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+
+		pod := job.Spec.Template.Spec
+		Expect(pod.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: serviceAccountTokensVolumeName, MountPath: "/var/run/secrets/tokens", ReadOnly: true,
+		}))
+		Expect(pod.InitContainers[0].VolumeMounts).ToNot(ContainElement(HaveField("Name", serviceAccountTokensVolumeName)))
+		Expect(pod.Volumes).To(ContainElement(t.getServiceAccountTokensVolume()))
+		Expect(t.getServiceAccountTokensVolume().Projected.Sources[0].ServiceAccountToken.Audience).To(Equal("sts.amazonaws.com"))
+	})
+})
@@ -60,6 +60,33 @@ func getVolumeMountSpec(volumeName string, mountPath string, readOnly bool) core
 	}
 }
 
+// getKeyProjection returns the projection of a configmap or a secret key to the given path
+func getKeyProjection(configMapKeyRef *corev1.ConfigMapKeySelector, secretKeyRef *corev1.SecretKeySelector, path string) []corev1.VolumeProjection {
+	sources := []corev1.VolumeProjection{}
+
+	if configMapKeyRef != nil {
+		sources = append(sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: configMapKeyRef.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: configMapKeyRef.Key, Path: path}},
+				Optional:             configMapKeyRef.Optional,
+			},
+		})
+	}
+
+	if secretKeyRef != nil {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: secretKeyRef.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: secretKeyRef.Key, Path: path}},
+				Optional:             secretKeyRef.Optional,
+			},
+		})
+	}
+
+	return sources
+}
+
 // getEnvVariable returns a Kubernetes environment variable spec
 func getEnvVariable(name string, value string) corev1.EnvVar {
 	return corev1.EnvVar{
@@ -95,6 +122,16 @@ func getUniqueResourceName(name string, runID string) string {
 	return fmt.Sprintf("%s-%s", truncateResourceName(name, 220), runID)
 }
 
+// getJobName returns the name of the job of the given type for the terraform Run
+// the apply job keeps the plain unique resource name
+func getJobName(name string, runID string, jobType RunJobType) string {
+	if jobType == ApplyJob {
+		return getUniqueResourceName(name, runID)
+	}
+
+	return fmt.Sprintf("%s-%s", getUniqueResourceName(name, runID), jobType)
+}
+
 // GetOutputSecretName returns a unique name for the terraform Run job
 func getOutputSecretName(runName string) string {
 	return fmt.Sprintf("%s-outputs", truncateResourceName(runName, 220))
@@ -0,0 +1,178 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// runnerPodTemplateFields are the fields the pod template of a workflow/run can set, by parent field. The fields
+// owned by the operator (e.g. the image, command & arguments of the runner container, the service account and
+// the init containers) can't be set, so a workflow/run can't change what the runner executes nor as whom
+var runnerPodTemplateFields = map[string][]string{
+	"":                {"metadata", "spec"},
+	"metadata":        {"annotations", "labels"},
+	"spec":            {"nodeSelector", "tolerations", "affinity", "securityContext", "priorityClassName", "volumes", "containers"},
+	"spec.containers": {"name", "resources", "securityContext", "env", "volumeMounts"},
+}
+
+// operatorEnvVarPrefixes are the prefixes of the environment variables set by the operator in some of the runner
+// jobs only, e.g. `TERRAFORM_PLAN_ONLY`, they can't be set by the pod template of a workflow/run either
+var operatorEnvVarPrefixes = []string{"TERRAFORM_", "ENGINE", "GIT_", "TF_CLI_", "TF_ENCRYPTION", "TF_PLUGIN_CACHE_DIR", "OUTPUT_SECRET_NAME", "POD_NAMESPACE"}
+
+// applyRunnerPodTemplate merges the pod template of the workflow/run into the pod template of a runner job,
+// the labels selecting the pods of the job and the restart policy can't be overridden
+func (t *TerraformManipulator) applyRunnerPodTemplate(template *corev1.PodTemplateSpec) error {
+	if t.Spec.Runner == nil || t.Spec.Runner.PodTemplate == nil {
+		return nil
+	}
+
+	if err := checkRunnerPodTemplate(t.Spec.Runner.PodTemplate.Raw, template); err != nil {
+		return err
+	}
+
+	original, err := json.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("unable to encode the runner pod template: %w", err)
+	}
+
+	patched, err := strategicpatch.StrategicMergePatch(original, t.Spec.Runner.PodTemplate.Raw, corev1.PodTemplateSpec{})
+	if err != nil {
+		return fmt.Errorf("unable to merge the runner pod template: %w", err)
+	}
+
+	merged := corev1.PodTemplateSpec{}
+
+	if err := json.Unmarshal(patched, &merged); err != nil {
+		return fmt.Errorf("invalid runner pod template: %w", err)
+	}
+
+	if merged.Labels == nil {
+		merged.Labels = map[string]string{}
+	}
+
+	for key, value := range template.Labels {
+		merged.Labels[key] = value
+	}
+
+	merged.Spec.RestartPolicy = template.Spec.RestartPolicy
+
+	*template = merged
+
+	return nil
+}
+
+// checkRunnerPodTemplate checks that the pod template of a workflow/run only sets the allowed fields, it can add
+// environment variables, volumes and volume mounts to the runner container but not override those of the operator
+func checkRunnerPodTemplate(raw []byte, template *corev1.PodTemplateSpec) error {
+	fields := map[string]interface{}{}
+
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return fmt.Errorf("invalid runner pod template: %w", err)
+	}
+
+	if err := checkRunnerPodTemplateFields("", fields); err != nil {
+		return err
+	}
+
+	// the unknown fields are rejected so typos don't go unnoticed
+	podTemplate := corev1.PodTemplateSpec{}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&podTemplate); err != nil {
+		return fmt.Errorf("invalid runner pod template: %w", err)
+	}
+
+	volumes := map[string]bool{}
+
+	for _, v := range podTemplate.Spec.Volumes {
+		if slices.ContainsFunc(template.Spec.Volumes, func(o corev1.Volume) bool { return o.Name == v.Name }) {
+			return fmt.Errorf("the runner pod template can't override the volume '%s' of the operator", v.Name)
+		}
+		volumes[v.Name] = true
+	}
+
+	runner := corev1.Container{}
+	if i := slices.IndexFunc(template.Spec.Containers, func(c corev1.Container) bool { return c.Name == runnerContainerName }); i >= 0 {
+		runner = template.Spec.Containers[i]
+	}
+
+	for _, c := range podTemplate.Spec.Containers {
+		if c.Name != runnerContainerName {
+			return fmt.Errorf("the runner pod template can only set the '%s' container, not '%s'", runnerContainerName, c.Name)
+		}
+
+		for _, env := range c.Env {
+			if isOperatorEnvVar(env.Name) || slices.ContainsFunc(runner.Env, func(o corev1.EnvVar) bool { return o.Name == env.Name }) {
+				return fmt.Errorf("the runner pod template can't set the environment variable '%s' of the operator", env.Name)
+			}
+		}
+
+		for _, m := range c.VolumeMounts {
+			if !volumes[m.Name] {
+				return fmt.Errorf("the runner pod template can only mount its own volumes, not '%s'", m.Name)
+			}
+
+			if slices.ContainsFunc(runner.VolumeMounts, func(o corev1.VolumeMount) bool { return o.MountPath == m.MountPath }) {
+				return fmt.Errorf("the runner pod template can't mount a volume at '%s', it is used by the operator", m.MountPath)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRunnerPodTemplateFields checks that the fields of a pod template (or of one of its parent fields) are allowed
+func checkRunnerPodTemplateFields(path string, fields map[string]interface{}) error {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := key
+		if path != "" {
+			fieldPath = fmt.Sprintf("%s.%s", path, key)
+		}
+
+		if !slices.Contains(runnerPodTemplateFields[path], key) {
+			return fmt.Errorf("the runner pod template can't set '%s', only %s", fieldPath, strings.Join(runnerPodTemplateFields[path], ", "))
+		}
+
+		if _, ok := runnerPodTemplateFields[fieldPath]; !ok {
+			continue
+		}
+
+		items := []interface{}{fields[key]}
+		if list, ok := fields[key].([]interface{}); ok {
+			items = list
+		}
+
+		for _, item := range items {
+			child, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid runner pod template: '%s' must be an object", fieldPath)
+			}
+
+			if err := checkRunnerPodTemplateFields(fieldPath, child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isOperatorEnvVar evaluates if an environment variable is reserved for the operator
+func isOperatorEnvVar(name string) bool {
+	return slices.ContainsFunc(operatorEnvVarPrefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) })
+}
@@ -0,0 +1,105 @@
+package terraform
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("Runner Pod Template", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.Spec.Variables = []v1alpha1.Variable{{Key: "length", Value: "16"}}
+		t.Status.RunID = "abc123"
+	})
+
+	setPodTemplate := func(template string) {
+		t.Spec.Runner = &v1alpha1.Runner{PodTemplate: &apiextensionsv1.JSON{Raw: []byte(template)}}
+	}
+
+	It("should merge the pod template into the pods of the job", func() {
+		setPodTemplate(`{
+			"metadata": {"annotations": {"cluster-autoscaler.kubernetes.io/safe-to-evict": "false"}},
+			"spec": {
+				"nodeSelector": {"pool": "terraform"},
+				"tolerations": [{"key": "dedicated", "operator": "Equal", "value": "terraform", "effect": "NoSchedule"}],
+				"priorityClassName": "batch",
+				"volumes": [{"name": "ca-bundle", "configMap": {"name": "ca-bundle"}}],
+				"containers": [{
+					"name": "terraform",
+					"resources": {"requests": {"memory": "2Gi"}},
+					"env": [{"name": "TF_LOG", "value": "DEBUG"}],
+					"volumeMounts": [{"name": "ca-bundle", "mountPath": "/etc/ssl/certs/ca-bundle"}]
+				}]
+			}
+		}`)
+
+		job, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+
+		pod := job.Spec.Template
+		Expect(pod.Annotations).To(HaveKeyWithValue("cluster-autoscaler.kubernetes.io/safe-to-evict", "false"))
+		Expect(pod.Labels).To(Equal(getCommonLabels("app", "abc123")))
+		Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{"pool": "terraform"}))
+		Expect(pod.Spec.Tolerations).To(HaveLen(1))
+		Expect(pod.Spec.PriorityClassName).To(Equal("batch"))
+		Expect(pod.Spec.ServiceAccountName).To(Equal(t.GetServiceAccountName().Name))
+		Expect(pod.Spec.InitContainers).To(HaveLen(1))
+		Expect(pod.Spec.Volumes).To(ContainElement(HaveField("Name", "ca-bundle")))
+
+		Expect(pod.Spec.Containers).To(HaveLen(1))
+		runner := pod.Spec.Containers[0]
+		Expect(runner.Image).To(Equal(getTerraformRunnerDockerImage()))
+		Expect(runner.Resources.Requests.Memory().Equal(resource.MustParse("2Gi"))).To(BeTrue())
+		Expect(runner.Env).To(ContainElements(
+			corev1.EnvVar{Name: "TF_VAR_length", Value: "16"},
+			corev1.EnvVar{Name: "TF_LOG", Value: "DEBUG"},
+		))
+		Expect(runner.VolumeMounts).To(ContainElement(HaveField("MountPath", "/etc/ssl/certs/ca-bundle")))
+	})
+
+	It("should keep the labels and the restart policy of the job", func() {
+		setPodTemplate(`{"metadata": {"labels": {"terraformRunId": "other", "team": "a"}}}`)
+
+		job, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(job.Spec.Template.Labels).To(HaveKeyWithValue("terraformRunId", "abc123"))
+		Expect(job.Spec.Template.Labels).To(HaveKeyWithValue("team", "a"))
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+	})
+
+	It("should reject overriding the plan-only mode of a plan job", func() {
+		setPodTemplate(`{"spec": {"containers": [{"name": "terraform", "env": [{"name": "TERRAFORM_PLAN_ONLY", "value": "false"}]}]}}`)
+
+		_, err := t.GetJobSpecForRun(PlanJob)
+		Expect(err).To(MatchError(ContainSubstring("can't set the environment variable 'TERRAFORM_PLAN_ONLY'")))
+
+		Expect(t.Validate()).To(HaveLen(1))
+	})
+
+	It("should reject the operator owned fields", func() {
+		for template, msg := range map[string]string{
+			`{"spec": {"serviceAccountName": "admin"}}`:                                                                      "can't set 'spec.serviceAccountName'",
+			`{"spec": {"initContainers": [{"name": "busybox", "image": "evil"}]}}`:                                           "can't set 'spec.initContainers'",
+			`{"spec": {"containers": [{"name": "terraform", "image": "evil"}]}}`:                                             "can't set 'spec.containers.image'",
+			`{"spec": {"containers": [{"name": "terraform", "command": ["sh"]}]}}`:                                           "can't set 'spec.containers.command'",
+			`{"spec": {"containers": [{"name": "sidecar"}]}}`:                                                                "can only set the 'terraform' container",
+			`{"spec": {"containers": [{"name": "terraform", "env": [{"name": "TF_VAR_length"}]}]}}`:                          "can't set the environment variable 'TF_VAR_length'",
+			`{"spec": {"volumes": [{"name": "tf-project", "emptyDir": {}}]}}`:                                                "can't override the volume 'tf-project'",
+			`{"spec": {"containers": [{"name": "terraform", "volumeMounts": [{"name": "tf-project", "mountPath": "/x"}]}]}}`: "can only mount its own volumes",
+			`{"spec": {"containers": [{"name": "terraform", "$patch": "delete"}]}}`:                                          "can't set 'spec.containers.$patch'",
+			`{"spec": {"nodeSelectr": {"pool": "terraform"}}}`:                                                               "can't set 'spec.nodeSelectr'",
+		} {
+			setPodTemplate(template)
+
+			_, err := t.GetJobSpecForRun(ApplyJob)
+			Expect(err).To(MatchError(ContainSubstring(msg)), template)
+		}
+	})
+})
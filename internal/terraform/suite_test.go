@@ -0,0 +1,42 @@
+package terraform
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"github.com/rinswind/terraform-operator/internal/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTerraform(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Terraform Suite")
+}
+
+// env is the operator configuration before each spec, the specs change the fields they test
+var env *utils.EnvConfig
+
+var _ = BeforeEach(func() {
+	env = utils.Env
+	utils.Env = &utils.EnvConfig{
+		DockerRepository:        "docker.io",
+		TerraformRunnerFeatures: []string{RunnerFeaturePlanOnly, RunnerFeatureDriftReport},
+	}
+})
+
+var _ = AfterEach(func() {
+	utils.Env = env
+})
+
+// newTestTerraform returns the workflow/run the specs start from, they set the fields they test
+func newTestTerraform() *TerraformManipulator {
+	return &TerraformManipulator{Terraform: &v1alpha1.Terraform{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: v1alpha1.TerraformSpec{
+			TerraformVersion: "1.0.2",
+			Module:           &v1alpha1.Module{Source: "IbraheemAlSaady/test/module"},
+		},
+	}}
+}
@@ -31,6 +31,11 @@ func (t *TerraformManipulator) IsRunning() bool {
 	return t.Status.RunStatus == v1alpha1.RunRunning
 }
 
+// IsCompleted evaluates that the workflow/run is completed
+func (t *TerraformManipulator) IsCompleted() bool {
+	return t.Status.RunStatus == v1alpha1.RunCompleted
+}
+
 // IsUpdated evaluates if the workflow/run was updated
 func (t *TerraformManipulator) IsUpdated() bool {
 	return t.Generation > 0 && t.Generation > t.Status.ObservedGeneration
@@ -41,6 +46,62 @@ func (t *TerraformManipulator) IsWaiting() bool {
 	return t.Status.RunStatus == v1alpha1.RunWaitingForDependency
 }
 
+// IsAwaitingApproval evaluates if the workflow/run was planned and is waiting for an approval
+func (t *TerraformManipulator) IsAwaitingApproval() bool {
+	return t.Status.RunStatus == v1alpha1.RunAwaitingApproval
+}
+
+// RequiresApproval evaluates if the workflow/run must be approved before it is applied
+func (t *TerraformManipulator) RequiresApproval() bool {
+	return t.Spec.ApprovalMode == v1alpha1.ApprovalManual
+}
+
+// IsApproved evaluates if the current workflow/run was approved
+func (t *TerraformManipulator) IsApproved() bool {
+	return t.Status.Approval != nil && t.Status.Approval.RunID == t.Status.RunID
+}
+
+// GetApproval returns the run ID and the approver set through the approval annotations
+func (t *TerraformManipulator) GetApproval() (string, string) {
+	annotations := t.GetAnnotations()
+
+	return annotations[v1alpha1.ApproveRunAnnotation], annotations[v1alpha1.ApproverAnnotation]
+}
+
+// RecordApprover sets the approver of an approval made by the request to the authenticated user who made it,
+// given the object before the request (nil on creation). Setting the approver otherwise is rejected, so the
+// recorded approver can't be claimed by whoever can update the workflow/run
+func (t *TerraformManipulator) RecordApprover(old *v1alpha1.Terraform, username string) error {
+	oldRunID, oldApprover := "", ""
+	if old != nil {
+		oldRunID, oldApprover = old.Annotations[v1alpha1.ApproveRunAnnotation], old.Annotations[v1alpha1.ApproverAnnotation]
+	}
+
+	runID, approver := t.GetApproval()
+
+	if runID != "" && runID != oldRunID {
+		t.Annotations[v1alpha1.ApproverAnnotation] = username
+		return nil
+	}
+
+	if approver != "" && approver != oldApprover {
+		return fmt.Errorf("the '%s' annotation is set by the operator to the user who approves the run with the '%s' annotation",
+			v1alpha1.ApproverAnnotation, v1alpha1.ApproveRunAnnotation)
+	}
+
+	return nil
+}
+
+// DestroysOnDelete evaluates if the infrastructure has to be destroyed before the object is deleted,
+// runs that were never submitted or already destroyed have nothing to destroy
+func (t *TerraformManipulator) DestroysOnDelete() bool {
+	if t.Spec.DeletionPolicy != v1alpha1.DeletionPolicyDestroy || t.IsSubmitted() {
+		return false
+	}
+
+	return !(t.Spec.Destroy && t.IsCompleted())
+}
+
 // HasErrored evaluates if the workflow/run failed
 func (t *TerraformManipulator) HasErrored() bool {
 	return t.Status.RunStatus == v1alpha1.RunFailed
@@ -2,72 +2,442 @@ package terraform
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
-	"text/template"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// mainFileName is the Terraform project file generated from the spec, it is written as Terraform JSON
+	// so the values of the spec are always escaped
+	mainFileName string = "main.tf.json"
+	// backendFileName is the Terraform project file holding the backend configuration of the spec
+	backendFileName string = "backend.tf"
+	// providersFileName is the Terraform project file holding the providers configuration of the spec
+	providersFileName string = "providers.tf"
+	// variablesFileName is the Terraform variables file holding the structured values of the variables,
+	// Terraform loads it automatically from the project directory
+	variablesFileName string = "operator.auto.tfvars.json"
+	// moduleName is the name of the module block calling the module of the spec
+	moduleName string = "operator"
+	// localModulesDir is the directory of the Terraform project the local modules are unpacked to, in a subdirectory
+	// named after their module call
+	localModulesDir string = "modules"
 )
 
-// getTerraformModuleFromTemplate generates the Terraform module template
-func (t *TerraformManipulator) getTerraformModuleFromTemplate() ([]byte, error) {
-	tfTemplate, err := template.New("main.tf").Parse(`terraform {
-		{{- if .Spec.Backend }}
-		{{.Spec.Backend}}
-		{{- end}}
-	
-		required_version = "~> {{.Spec.TerraformVersion}}"
-	}
-
-	{{- if .Spec.ProvidersConfig }}
-	{{.Spec.ProvidersConfig}}
-	{{- end}}
-	
-	{{- range .Spec.Variables}}
-	{{- if not .EnvironmentVariable }}
-	variable "{{.Key}}" {}
-	{{- end}}
-	{{- end}}
-	
-	## additional-blocks
-	
-	module "operator" {
-		source = "{{.Spec.Module.Source}}"
-		
-		{{- if .Spec.Module.Version }}
-		version = "{{.Spec.Module.Version}}"
-		{{- end}}
-	
-		{{- range .Spec.Variables}}
-		{{- if not .EnvironmentVariable }}
-		{{.Key}} = var.{{.Key}}
-		{{- end}}
-		{{- end}}
-	}
-	
-	{{- range .Spec.Outputs}}
-	output "{{.Key}}" {
-		value = module.operator.{{.ModuleOutputName}}
-	}
-	{{- end}}`)
+// identifierRegex matches the valid Terraform identifiers
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// generatedFileNames are the Terraform project files generated by the operator
+var generatedFileNames = map[string]bool{
+	mainFileName:         true,
+	backendFileName:      true,
+	providersFileName:    true,
+	variablesFileName:    true,
+	cliConfigFileName:    true,
+	typedBackendFileName: true,
+}
+
+// reservedVariableNames are the arguments of a module block that can't be used as module variables
+var reservedVariableNames = map[string]bool{
+	"source":     true,
+	"version":    true,
+	"providers":  true,
+	"count":      true,
+	"for_each":   true,
+	"depends_on": true,
+	"lifecycle":  true,
+	"locals":     true,
+}
 
+// getTerraformProjectFiles generates the files of the Terraform project of the workflow/run, keyed by file name
+func (t *TerraformManipulator) getTerraformProjectFiles() (map[string]string, error) {
+	if err := t.checkEngine(); err != nil {
+		return nil, fmt.Errorf("invalid engine: %w", err)
+	}
+
+	main, err := t.getTerraformMainFile()
 	if err != nil {
 		return nil, err
 	}
-	var tpl bytes.Buffer
 
-	if err := tfTemplate.Execute(&tpl, t); err != nil {
+	files := map[string]string{
+		mainFileName: string(main),
+	}
+
+	values, err := t.getTerraformVariablesFile()
+	if err != nil {
 		return nil, err
 	}
 
-	return tpl.Bytes(), nil
+	if values != nil {
+		files[variablesFileName] = string(values)
+	}
+
+	if t.Spec.Backend != "" {
+		if err := checkHCLBlocks(t.Spec.Backend, "backend"); err != nil {
+			return nil, fmt.Errorf("invalid backend configuration: %w", err)
+		}
+
+		files[backendFileName] = fmt.Sprintf("terraform {\n%s\n}\n", t.Spec.Backend)
+	} else if t.Spec.TypedBackend == nil {
+		// the workflows/runs created before the backend was defaulted keep their state in the default backend too
+		files[backendFileName] = fmt.Sprintf("terraform {\n%s\n}\n", t.getDefaultBackend())
+	}
+
+	if t.Spec.TypedBackend != nil {
+		if t.Spec.Backend != "" {
+			return nil, fmt.Errorf("the backend configuration can't be combined with a typed backend")
+		}
+
+		backend, err := t.getTypedBackendFile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid typed backend: %w", err)
+		}
+
+		files[typedBackendFileName] = string(backend)
+	}
+
+	if t.Spec.ProvidersConfig != "" {
+		if len(t.Spec.TypedProviders) > 0 {
+			return nil, fmt.Errorf("the providers configuration can't be combined with typed providers")
+		}
+
+		if err := checkHCLBlocks(t.Spec.ProvidersConfig, "terraform", "provider"); err != nil {
+			return nil, fmt.Errorf("invalid providers configuration: %w", err)
+		}
+
+		files[providersFileName] = t.Spec.ProvidersConfig
+	}
+
+	terragrunt, err := t.getTerragruntConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if terragrunt != nil {
+		files[terragruntConfigFileName] = string(terragrunt)
+	}
+
+	cliConfig, err := t.getTerraformCLIConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if cliConfig != "" {
+		files[cliConfigFileName] = cliConfig
+	}
+
+	// the extra files from secrets & configmaps are added to the project by the job
+	for _, f := range t.Spec.ExtraFiles {
+		if f.ValueFrom != nil {
+			continue
+		}
+
+		if err := checkExtraFileName(f.Name); err != nil {
+			return nil, err
+		}
+
+		if _, ok := files[f.Name]; ok {
+			return nil, fmt.Errorf("extra file '%s' is defined more than once", f.Name)
+		}
+
+		files[f.Name] = f.Content
+	}
+
+	// the files are parsed as Terraform would, so a broken file fails the run before the ConfigMap is created
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := checkTerraformFile(name, files[name]); err != nil {
+			return nil, fmt.Errorf("invalid project file '%s': %w", name, err)
+		}
+	}
+
+	return files, nil
 }
 
-// setBackendCfgIfNotExist sets the default backend to Kunernetes if not provided
-func (t *TerraformManipulator) setBackendCfgIfNotExist() {
-	if t.Spec.Backend == "" {
-		t.Spec.Backend = fmt.Sprintf(`backend "kubernetes" {
-  secret_suffix     = "%s"
-  in_cluster_config = true
-  namespace         = "%s"
+// checkExtraFileName checks that the name of an extra file is a Terraform file name
+// that doesn't override the files generated by the operator
+func checkExtraFileName(name string) error {
+	if !strings.HasSuffix(name, ".tf") && !strings.HasSuffix(name, ".tf.json") {
+		return fmt.Errorf("extra file '%s' must have a .tf or .tf.json extension", name)
+	}
+
+	if msgs := validation.IsConfigMapKey(name); len(msgs) > 0 {
+		return fmt.Errorf("extra file '%s' is not a valid file name: %s", name, strings.Join(msgs, ", "))
+	}
+
+	if generatedFileNames[name] {
+		return fmt.Errorf("extra file '%s' overrides a file generated by the operator", name)
+	}
+
+	return nil
 }
-`, t.ObjectMeta.Name, t.ObjectMeta.Namespace)
+
+// getTerraformMainFile generates the Terraform JSON file calling the modules of the workflow/run
+// with their inputs and exposing their outputs
+func (t *TerraformManipulator) getTerraformMainFile() ([]byte, error) {
+	variables := map[string]interface{}{}
+
+	for _, v := range t.Spec.Variables {
+		if v.EnvironmentVariable {
+			continue
+		}
+
+		if !identifierRegex.MatchString(v.Key) || reservedVariableNames[v.Key] {
+			return nil, fmt.Errorf("variable key '%s' is not a valid module variable name", v.Key)
+		}
+
+		declaration := map[string]interface{}{}
+
+		if v.Type != "" {
+			if err := checkHCLExpression(v.Type); err != nil {
+				return nil, fmt.Errorf("invalid type of variable '%s': %w", v.Key, err)
+			}
+			declaration["type"] = v.Type
+		}
+
+		if v.Sensitive {
+			declaration["sensitive"] = true
+		}
+
+		if v.Nullable != nil {
+			declaration["nullable"] = *v.Nullable
+		}
+
+		variables[v.Key] = declaration
+	}
+
+	modules, err := t.getModuleCalls(variables)
+	if err != nil {
+		return nil, err
 	}
+
+	// the provider variables are declared after the module calls, they are not inputs of the spec module
+	providers, err := t.getTypedProviderBlocks()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, declaration := range providers.variables {
+		if _, ok := variables[name]; ok {
+			return nil, fmt.Errorf("variable '%s' of a provider argument is already declared", name)
+		}
+
+		variables[name] = declaration
+	}
+
+	outputs := map[string]interface{}{}
+
+	for _, o := range t.Spec.Outputs {
+		if o == nil {
+			continue
+		}
+
+		if !identifierRegex.MatchString(o.Key) {
+			return nil, fmt.Errorf("output key '%s' is not a valid output name", o.Key)
+		}
+
+		if !identifierRegex.MatchString(o.ModuleOutputName) {
+			return nil, fmt.Errorf("module output name '%s' is not a valid output name", o.ModuleOutputName)
+		}
+
+		module := getOutputModuleName(o)
+		if _, ok := modules[module]; !ok {
+			return nil, fmt.Errorf("output '%s' references the unknown module '%s'", o.Key, module)
+		}
+
+		outputs[o.Key] = map[string]interface{}{
+			"value": fmt.Sprintf("${module.%s.%s}", module, o.ModuleOutputName),
+		}
+	}
+
+	terraform := map[string]interface{}{
+		"required_version": t.getRequiredVersion(),
+	}
+
+	if len(providers.required) > 0 {
+		terraform["required_providers"] = providers.required
+	}
+
+	project := map[string]interface{}{
+		"terraform": terraform,
+		"module":    modules,
+	}
+
+	if len(providers.providers) > 0 {
+		project["provider"] = providers.providers
+	}
+
+	if len(variables) > 0 {
+		project["variable"] = variables
+	}
+
+	if len(outputs) > 0 {
+		project["output"] = outputs
+	}
+
+	return marshalTerraformJSON(mainFileName, project)
+}
+
+// getModuleCalls returns the module blocks of the workflow/run keyed by name, the spec module is called
+// with all the declared variables while the named module calls only get their explicit inputs
+func (t *TerraformManipulator) getModuleCalls(variables map[string]interface{}) (map[string]interface{}, error) {
+	modules := map[string]interface{}{}
+
+	if t.Spec.Module != nil {
+		module, err := t.getModuleBlock(moduleName, *t.Spec.Module)
+		if err != nil {
+			return nil, err
+		}
+
+		for key := range variables {
+			module[key] = fmt.Sprintf("${var.%s}", key)
+		}
+
+		modules[moduleName] = module
+	}
+
+	calls := map[string]bool{}
+	for _, m := range t.Spec.Modules {
+		calls[m.Name] = true
+	}
+
+	for _, m := range t.Spec.Modules {
+		if !identifierRegex.MatchString(m.Name) || m.Name == moduleName {
+			return nil, fmt.Errorf("module name '%s' is not a valid module call name", m.Name)
+		}
+
+		if _, ok := modules[m.Name]; ok {
+			return nil, fmt.Errorf("module '%s' is defined more than once", m.Name)
+		}
+
+		module, err := t.getModuleBlock(m.Name, m.Module)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, in := range m.Inputs {
+			if !identifierRegex.MatchString(in.Name) || reservedVariableNames[in.Name] {
+				return nil, fmt.Errorf("input '%s' of module '%s' is not a valid module variable name", in.Name, m.Name)
+			}
+
+			switch {
+			case in.Variable != "" && in.ModuleOutput == nil:
+				if _, ok := variables[in.Variable]; !ok {
+					return nil, fmt.Errorf("input '%s' of module '%s' references the unknown variable '%s'", in.Name, m.Name, in.Variable)
+				}
+
+				module[in.Name] = fmt.Sprintf("${var.%s}", in.Variable)
+			case in.Variable == "" && in.ModuleOutput != nil:
+				ref := in.ModuleOutput
+
+				if !calls[ref.Module] || ref.Module == m.Name {
+					return nil, fmt.Errorf("input '%s' of module '%s' references the unknown module '%s'", in.Name, m.Name, ref.Module)
+				}
+
+				if !identifierRegex.MatchString(ref.Output) {
+					return nil, fmt.Errorf("input '%s' of module '%s' references the invalid output '%s'", in.Name, m.Name, ref.Output)
+				}
+
+				module[in.Name] = fmt.Sprintf("${module.%s.%s}", ref.Module, ref.Output)
+			default:
+				return nil, fmt.Errorf("input '%s' of module '%s' must set exactly one of variable or moduleOutput", in.Name, m.Name)
+			}
+		}
+
+		modules[m.Name] = module
+	}
+
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no module to call, either module or modules is required")
+	}
+
+	return modules, nil
+}
+
+// getModuleBlock returns the source, version & providers arguments of a module block, a local module
+// is sourced from the directory it's unpacked to
+func (t *TerraformManipulator) getModuleBlock(name string, m v1alpha1.Module) (map[string]interface{}, error) {
+	module := map[string]interface{}{}
+
+	if m.SourceFrom != nil {
+		module["source"] = getLocalModuleSource(name)
+	} else {
+		module["source"] = m.Source
+
+		if m.Version != "" {
+			module["version"] = m.Version
+		}
+	}
+
+	providers, err := t.getModuleProviders(name, m)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(providers) > 0 {
+		module["providers"] = providers
+	}
+
+	return module, nil
+}
+
+// getLocalModuleSource returns the source of a local module relative to the Terraform project
+func getLocalModuleSource(name string) string {
+	return fmt.Sprintf("./%s/%s", localModulesDir, name)
+}
+
+// getOutputModuleName returns the name of the module call an output is read from
+func getOutputModuleName(o *v1alpha1.Output) string {
+	if o.Module == "" {
+		return moduleName
+	}
+
+	return o.Module
+}
+
+// getTerraformVariablesFile generates the Terraform JSON variables file with the structured values of the
+// variables of the workflow/run, it returns nil if no variable has a structured value
+func (t *TerraformManipulator) getTerraformVariablesFile() ([]byte, error) {
+	values := map[string]json.RawMessage{}
+
+	for _, v := range t.Spec.Variables {
+		if v.EnvironmentVariable || v.JSONValue == nil {
+			continue
+		}
+
+		values[v.Key] = v.JSONValue.Raw
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return marshalTerraformJSON(variablesFileName, values)
+}
+
+// marshalTerraformJSON encodes a Terraform JSON file, the HTML characters of the values are kept as is
+func marshalTerraformJSON(fileName string, v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("unable to generate %s: %w", fileName, err)
+	}
+
+	return buf.Bytes(), nil
 }
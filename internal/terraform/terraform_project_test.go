@@ -0,0 +1,335 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+var _ = Describe("Terraform Project", func() {
+	var t *TerraformManipulator
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.Spec.Module.Version = "0.0.1"
+		t.Spec.Variables = []v1alpha1.Variable{
+			{Key: "length", Value: "16"},
+			{Key: "AWS_REGION", Value: "eu-west-1", EnvironmentVariable: true},
+		}
+		t.Spec.Outputs = []*v1alpha1.Output{{Key: "result", ModuleOutputName: "result"}}
+	})
+
+	Context("Main file", func() {
+		It("should call the module with the variables and expose the outputs", func() {
+			files, err := t.getTerraformProjectFiles()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(HaveLen(2))
+			Expect(files).To(HaveKey(backendFileName))
+
+			project := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(files[mainFileName]), &project)).To(Succeed())
+
+			Expect(project["terraform"]).To(Equal(map[string]interface{}{"required_version": "~> 1.0.2"}))
+			Expect(project["variable"]).To(Equal(map[string]interface{}{"length": map[string]interface{}{}}))
+			Expect(project["module"]).To(Equal(map[string]interface{}{
+				"operator": map[string]interface{}{
+					"source":  "IbraheemAlSaady/test/module",
+					"version": "0.0.1",
+					"length":  "${var.length}",
+				},
+			}))
+			Expect(project["output"]).To(Equal(map[string]interface{}{
+				"result": map[string]interface{}{"value": "${module.operator.result}"},
+			}))
+		})
+
+		It("should escape the values of the spec", func() {
+			t.Spec.Module.Source = `IbraheemAlSaady/test/module" } resource "null_resource" "x" {`
+
+			files, err := t.getTerraformProjectFiles()
+			Expect(err).ToNot(HaveOccurred())
+
+			project := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(files[mainFileName]), &project)).To(Succeed())
+
+			Expect(project["module"].(map[string]interface{})["operator"].(map[string]interface{})["source"]).To(Equal(t.Spec.Module.Source))
+			Expect(project).ToNot(HaveKey("resource"))
+		})
+
+		It("should declare typed variables and pass their structured values in a variables file", func() {
+			nullable := false
+			t.Spec.Variables = []v1alpha1.Variable{
+				{
+					Key:       "subnets",
+					Type:      "map(object({ cidr = string }))",
+					Sensitive: true,
+					Nullable:  &nullable,
+					JSONValue: &apiextensionsv1.JSON{Raw: []byte(`{"a":{"cidr":"10.0.1.0/24"}}`)},
+				},
+			}
+
+			files, err := t.getTerraformProjectFiles()
+			Expect(err).ToNot(HaveOccurred())
+
+			project := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(files[mainFileName]), &project)).To(Succeed())
+
+			Expect(project["variable"]).To(Equal(map[string]interface{}{
+				"subnets": map[string]interface{}{
+					"type":      "map(object({ cidr = string }))",
+					"sensitive": true,
+					"nullable":  false,
+				},
+			}))
+
+			values := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(files[variablesFileName]), &values)).To(Succeed())
+			Expect(values).To(Equal(map[string]interface{}{
+				"subnets": map[string]interface{}{"a": map[string]interface{}{"cidr": "10.0.1.0/24"}},
+			}))
+		})
+
+		It("should reject incomplete variable types", func() {
+			t.Spec.Variables = []v1alpha1.Variable{{Key: "tags", Type: "map(string"}}
+
+			_, err := t.getTerraformProjectFiles()
+			Expect(err).To(MatchError(ContainSubstring("invalid type of variable 'tags'")))
+		})
+
+		It("should reject variables that aren't identifiers", func() {
+			t.Spec.Variables = []v1alpha1.Variable{{Key: "length = 1\n  count", Value: "16"}}
+
+			_, err := t.getTerraformProjectFiles()
+			Expect(err).To(MatchError(ContainSubstring("is not a valid module variable name")))
+		})
+	})
+
+	Context("Module calls", func() {
+		BeforeEach(func() {
+			t.Spec.Module = nil
+			t.Spec.Variables = []v1alpha1.Variable{{Key: "cidr", Value: "10.0.0.0/16"}}
+			t.Spec.Modules = []v1alpha1.ModuleCall{
+				{Name: "vpc", Module: v1alpha1.Module{Source: "terraform-aws-modules/vpc/aws", Version: "5.0.0"}, Inputs: []v1alpha1.ModuleInput{
+					{Name: "cidr", Variable: "cidr"},
+				}},
+				{Name: "eks", Module: v1alpha1.Module{Source: "terraform-aws-modules/eks/aws"}, Inputs: []v1alpha1.ModuleInput{
+					{Name: "vpc_id", ModuleOutput: &v1alpha1.ModuleOutputRef{Module: "vpc", Output: "vpc_id"}},
+				}},
+			}
+			t.Spec.Outputs = []*v1alpha1.Output{
+				{Key: "vpc_id", ModuleOutputName: "vpc_id", Module: "vpc"},
+				{Key: "cluster", ModuleOutputName: "cluster_name", Module: "eks"},
+			}
+		})
+
+		It("should wire the inputs between the module calls and expose their outputs", func() {
+			files, err := t.getTerraformProjectFiles()
+			Expect(err).ToNot(HaveOccurred())
+
+			project := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(files[mainFileName]), &project)).To(Succeed())
+
+			Expect(project["module"]).To(Equal(map[string]interface{}{
+				"vpc": map[string]interface{}{
+					"source":  "terraform-aws-modules/vpc/aws",
+					"version": "5.0.0",
+					"cidr":    "${var.cidr}",
+				},
+				"eks": map[string]interface{}{
+					"source": "terraform-aws-modules/eks/aws",
+					"vpc_id": "${module.vpc.vpc_id}",
+				},
+			}))
+			Expect(project["output"]).To(Equal(map[string]interface{}{
+				"vpc_id":  map[string]interface{}{"value": "${module.vpc.vpc_id}"},
+				"cluster": map[string]interface{}{"value": "${module.eks.cluster_name}"},
+			}))
+		})
+
+		It("should reject references to unknown modules", func() {
+			t.Spec.Modules[1].Inputs[0].ModuleOutput.Module = "network"
+
+			_, err := t.getTerraformProjectFiles()
+			Expect(err).To(MatchError(ContainSubstring("references the unknown module 'network'")))
+		})
+
+		It("should reject outputs of the spec module when it isn't set", func() {
+			t.Spec.Outputs = []*v1alpha1.Output{{Key: "result", ModuleOutputName: "result"}}
+
+			_, err := t.getTerraformProjectFiles()
+			Expect(err).To(MatchError(ContainSubstring("references the unknown module 'operator'")))
+		})
+	})
+
+	Context("Local modules", func() {
+		It("should source the module from the directory the job unpacks it to", func() {
+			t.Spec.Module = &v1alpha1.Module{SourceFrom: &v1alpha1.ModuleSourceFrom{
+				ConfigMaps: []corev1.LocalObjectReference{{Name: "glue-module-1"}, {Name: "glue-module-2"}},
+				Archive: []v1alpha1.ExtraFileSource{
+					{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "glue-module-1"}, Key: "templates.tar.gz"}},
+				},
+			}}
+
+			files, err := t.getTerraformProjectFiles()
+			Expect(err).ToNot(HaveOccurred())
+
+			project := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(files[mainFileName]), &project)).To(Succeed())
+			Expect(project["module"].(map[string]interface{})["operator"].(map[string]interface{})["source"]).To(Equal("./modules/operator"))
+
+			job, err := t.GetJobSpecForRun(ApplyJob)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(job.Spec.Template.Spec.InitContainers[0].Args[0]).To(HaveSuffix(
+				" && mkdir -p /tmp/tf-project/modules/operator" +
+					" && cp -v /terraform/module-sources/operator/* /tmp/tf-project/modules/operator" +
+					" && cat /terraform/module-sources/operator/.archive-0 | tar -xzvf - -C /tmp/tf-project/modules/operator"))
+			Expect(job.Spec.Template.Spec.InitContainers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name: "module-source-0", MountPath: "/terraform/module-sources/operator", ReadOnly: true,
+			}))
+			Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+				Name: "module-source-0",
+				VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+					{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "glue-module-1"}}},
+					{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "glue-module-2"}}},
+					{ConfigMap: &corev1.ConfigMapProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "glue-module-1"},
+						Items:                []corev1.KeyToPath{{Key: "templates.tar.gz", Path: ".archive-0"}},
+					}},
+				}}},
+			}))
+		})
+	})
+
+	Context("Backend and providers", func() {
+		It("should write them to their own files", func() {
+			t.SetDefaults()
+			t.Spec.ProvidersConfig = `provider "aws" {
+  region = "eu-west-1"
+}`
+
+			files, err := t.getTerraformProjectFiles()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(files[backendFileName]).To(HavePrefix("terraform {\nbackend \"kubernetes\""))
+			Expect(files[providersFileName]).To(Equal(t.Spec.ProvidersConfig))
+		})
+
+		It("should keep the state of an existing workflow/run without a backend in the backend its runner can access", func() {
+			// an object created before the defaults, the webhook only defaults new objects
+			Expect(t.Spec.Backend).To(BeEmpty())
+
+			files, err := t.getTerraformProjectFiles()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files[backendFileName]).To(Equal(fmt.Sprintf("terraform {\n%s\n}\n", t.getDefaultBackend())))
+
+			rules := t.getRunnerPolicyRules()
+			Expect(rules).To(HaveLen(5))
+			Expect(rules[1].ResourceNames).To(Equal([]string{"tfstate-default-app"}))
+		})
+
+		It("should reject a backend breaking out of the terraform block", func() {
+			t.Spec.Backend = `backend "local" {}
+}
+
+resource "null_resource" "x" {`
+
+			_, err := t.getTerraformProjectFiles()
+			Expect(err).To(MatchError(ContainSubstring("invalid backend configuration")))
+		})
+	})
+
+	Context("Extra files", func() {
+		It("should add the inline files to the project and mount the others in the job", func() {
+			t.Spec.ExtraFiles = []v1alpha1.ExtraFile{
+				{Name: "locals.tf", Content: `locals { name = "test" }`},
+				{Name: "moved.tf", ValueFrom: &v1alpha1.ExtraFileSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "refactoring"}, Key: "moved"},
+				}},
+			}
+
+			files, err := t.getTerraformProjectFiles()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(HaveKeyWithValue("locals.tf", `locals { name = "test" }`))
+			Expect(files).ToNot(HaveKey("moved.tf"))
+
+			job, err := t.GetJobSpecForRun(ApplyJob)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(job.Spec.Template.Spec.InitContainers[0].Args[0]).To(ContainSubstring("cp -v /terraform/extra-files/* /tmp/tf-project"))
+			Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+				Name: extraFilesVolumeName,
+				VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{{
+					ConfigMap: &corev1.ConfigMapProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "refactoring"},
+						Items:                []corev1.KeyToPath{{Key: "moved", Path: "moved.tf"}},
+					},
+				}}}},
+			}))
+		})
+
+		It("should reject files overriding the generated files", func() {
+			t.Spec.ExtraFiles = []v1alpha1.ExtraFile{{Name: mainFileName, Content: "{}"}}
+
+			_, err := t.getTerraformProjectFiles()
+			Expect(err).To(MatchError(ContainSubstring("overrides a file generated by the operator")))
+		})
+
+		It("should reject files that Terraform can't parse before the ConfigMap is created", func() {
+			t.Spec.ExtraFiles = []v1alpha1.ExtraFile{{Name: "locals.tf.json", Content: `{"locals": {"name": "test"}`}}
+
+			_, err := t.GetConfigMapSpecForModule()
+			Expect(err).To(MatchError(ContainSubstring("invalid project file 'locals.tf.json'")))
+		})
+	})
+
+	Context("HCL blocks", func() {
+		It("should accept complete blocks", func() {
+			src := `# the aws provider
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 3.0" // pinned
+    }
+  }
+}
+
+provider "aws" {
+  region = "eu-${var.region == "}" ? "west" : "central"}-1"
+  /* a } in a comment */
+  policy = <<-EOT
+    }
+  EOT
+}
+`
+			Expect(checkHCLBlocks(src, "terraform", "provider")).To(Succeed())
+		})
+
+		It("should reject unexpected blocks, attributes and unterminated sequences", func() {
+			Expect(checkHCLBlocks(`resource "null_resource" "x" {}`, "provider")).To(MatchError(ContainSubstring("only provider blocks are allowed")))
+			Expect(checkHCLBlocks(`provider = "aws"`, "provider")).To(MatchError("unexpected 'provider' on line 1, expected a block"))
+			Expect(checkHCLBlocks(`provider "aws" { region = "eu-west-1 }`, "provider")).To(MatchError(ContainSubstring("Unterminated template string on line 1")))
+			Expect(checkHCLBlocks(`provider "aws" { tags = [ }`, "provider")).To(MatchError(ContainSubstring("Invalid expression on line 1")))
+			Expect(checkHCLBlocks("provider \"aws\" {\n}\n}\nresource \"null_resource\" \"x\" {", "provider")).To(HaveOccurred())
+		})
+
+		It("should reject incomplete expressions", func() {
+			Expect(checkHCLExpression(`map(object({ name = string }))`)).To(Succeed())
+			Expect(checkHCLExpression(`list(string)) + (`)).To(MatchError(ContainSubstring("Extra characters after expression")))
+		})
+
+		It("should reject the project files that Terraform can't parse", func() {
+			Expect(checkTerraformFile("main.tf.json", `{"module": {}}`)).To(Succeed())
+			Expect(checkTerraformFile("main.tf.json", `{"module": `)).To(HaveOccurred())
+			Expect(checkTerraformFile("main.tf.json", `["module"]`)).To(MatchError(ContainSubstring("A JSON object is required")))
+			Expect(checkTerraformFile("main.tf", `output "x" { value = 1 }`)).To(Succeed())
+			Expect(checkTerraformFile("main.tf", `output "x" {`)).To(MatchError(ContainSubstring("Unclosed configuration block")))
+		})
+	})
+})
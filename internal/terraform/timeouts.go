@@ -0,0 +1,183 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// jobTimeoutAnnotation holds the reason a runner job was stopped by the operator for timing out
+const jobTimeoutAnnotation string = "run.terraform-operator.io/timeout"
+
+// getJobDeadline returns the active deadline of a runner job in seconds, the plan timeout for the plan
+// and drift jobs and the apply timeout for the apply and destroy jobs, none unless set. The deadline covers
+// the whole job, from its pending pods to its last retry
+func (t *TerraformManipulator) getJobDeadline(jobType RunJobType) *int64 {
+	if t.Spec.Timeouts == nil {
+		return nil
+	}
+
+	timeout := t.Spec.Timeouts.Apply
+	if jobType == PlanJob || jobType == DriftJob {
+		timeout = t.Spec.Timeouts.Plan
+	}
+
+	if timeout == nil {
+		return nil
+	}
+
+	seconds := int64(math.Ceil(timeout.Seconds()))
+
+	return &seconds
+}
+
+// CheckJobTimeouts returns the reason a runner job of the workflow/run timed out, empty unless it did. The job
+// fails past its deadline, while a pod stuck pending or in its init container is stopped by suspending the job
+// so it doesn't run once it gets unstuck
+func (t *TerraformManipulator) CheckJobTimeouts(ctx context.Context, c client.Client, job *batchv1.Job, jobType RunJobType) (string, error) {
+	if reason, ok := job.Annotations[jobTimeoutAnnotation]; ok {
+		return reason, nil
+	}
+
+	if isJobConditionTrue(job, batchv1.JobFailed, batchv1.JobReasonDeadlineExceeded) && job.Spec.ActiveDeadlineSeconds != nil {
+		return fmt.Sprintf("%s job '%s' exceeded its timeout of %s", jobType, job.Name, time.Duration(*job.Spec.ActiveDeadlineSeconds)*time.Second), nil
+	}
+
+	if t.Spec.Timeouts == nil || job.Status.Succeeded > 0 || isJobConditionTrue(job, batchv1.JobFailed, "") {
+		return "", nil
+	}
+
+	reason, err := t.getStuckPodReason(ctx, c, job)
+	if err != nil || reason == "" {
+		return "", err
+	}
+
+	suspend := true
+
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[jobTimeoutAnnotation] = reason
+	job.Spec.Suspend = &suspend
+
+	if err := c.Update(ctx, job); err != nil {
+		return "", err
+	}
+
+	return reason, nil
+}
+
+// getStuckPodReason returns why the pod of a runner job is stuck, empty unless it exceeded the pending or the init container timeout
+func (t *TerraformManipulator) getStuckPodReason(ctx context.Context, c client.Client, job *batchv1.Job) (string, error) {
+	pods := &corev1.PodList{}
+
+	if err := c.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		return "", err
+	}
+
+	timeouts := t.Spec.Timeouts
+	now := time.Now()
+
+	// the pod couldn't be created, e.g. its service account doesn't exist or a quota is exceeded
+	if len(pods.Items) == 0 {
+		if timeouts.Pending != nil && job.Status.Active == 0 && now.Sub(job.CreationTimestamp.Time) > timeouts.Pending.Duration {
+			return fmt.Sprintf("job '%s' created no pod in %s, check the events of the job", job.Name, timeouts.Pending.Duration), nil
+		}
+
+		return "", nil
+	}
+
+	for i := range pods.Items {
+		if reason := getPodTimeoutReason(&pods.Items[i], timeouts, now); reason != "" {
+			return reason, nil
+		}
+	}
+
+	return "", nil
+}
+
+// getPodTimeoutReason returns why a pending pod timed out, either its init container ran longer than the init
+// container timeout or the pod waited longer than the pending timeout to be scheduled or to start its containers
+func getPodTimeoutReason(pod *corev1.Pod, timeouts *v1alpha1.Timeouts, now time.Time) string {
+	if pod.Status.Phase != corev1.PodPending || pod.DeletionTimestamp != nil {
+		return ""
+	}
+
+	pendingSince := pod.CreationTimestamp.Time
+
+	for _, s := range pod.Status.InitContainerStatuses {
+		if running := s.State.Running; running != nil {
+			if timeouts.InitContainer != nil && now.Sub(running.StartedAt.Time) > timeouts.InitContainer.Duration {
+				return fmt.Sprintf("init container '%s' of pod '%s' didn't complete in %s", s.Name, pod.Name, timeouts.InitContainer.Duration)
+			}
+
+			return ""
+		}
+
+		// the pod is pending again while the runner container starts
+		if terminated := s.State.Terminated; terminated != nil && terminated.FinishedAt.After(pendingSince) {
+			pendingSince = terminated.FinishedAt.Time
+		}
+	}
+
+	if timeouts.Pending == nil || now.Sub(pendingSince) <= timeouts.Pending.Duration {
+		return ""
+	}
+
+	reason := fmt.Sprintf("pod '%s' was pending for more than %s", pod.Name, timeouts.Pending.Duration)
+
+	if cause := getPodPendingCause(pod); cause != "" {
+		reason = fmt.Sprintf("%s, %s", reason, cause)
+	}
+
+	return reason
+}
+
+// getPodPendingCause returns why a pod is pending, it can't be scheduled or one of its containers can't start
+func getPodPendingCause(pod *corev1.Pod) string {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason != "" {
+			return fmt.Sprintf("it is %s", formatReason(cond.Reason, cond.Message))
+		}
+	}
+
+	statuses := append(slices.Clone(pod.Status.InitContainerStatuses), pod.Status.ContainerStatuses...)
+
+	for _, s := range statuses {
+		waiting := s.State.Waiting
+		if waiting == nil || waiting.Reason == "" || waiting.Reason == "PodInitializing" || waiting.Reason == "ContainerCreating" {
+			continue
+		}
+
+		return fmt.Sprintf("container '%s' is in %s", s.Name, formatReason(waiting.Reason, waiting.Message))
+	}
+
+	return ""
+}
+
+// isJobConditionTrue evaluates if a job has a true condition of the given type, with the given reason if any
+func isJobConditionTrue(job *batchv1.Job, condType batchv1.JobConditionType, reason string) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == condType && cond.Status == corev1.ConditionTrue && (reason == "" || cond.Reason == reason) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatReason returns a reason followed by its message, if any
+func formatReason(reason string, message string) string {
+	if message == "" {
+		return reason
+	}
+
+	return fmt.Sprintf("%s (%s)", reason, message)
+}
@@ -0,0 +1,172 @@
+package terraform
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Run Timeouts", func() {
+	var t *TerraformManipulator
+	var c client.Client
+	var job *batchv1.Job
+
+	ctx := context.Background()
+	started := metav1.NewTime(time.Now().Add(-20 * time.Minute))
+
+	newPod := func(status corev1.PodStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "app-apply-pod",
+				Namespace:         "default",
+				Labels:            map[string]string{batchv1.JobNameLabel: job.Name},
+				CreationTimestamp: started,
+			},
+			Status: status,
+		}
+	}
+
+	BeforeEach(func() {
+		t = newTestTerraform()
+		t.Spec.Timeouts = &v1alpha1.Timeouts{
+			InitContainer: &metav1.Duration{Duration: 5 * time.Minute},
+			Plan:          &metav1.Duration{Duration: 30 * time.Minute},
+			Apply:         &metav1.Duration{Duration: 90 * time.Second},
+			Pending:       &metav1.Duration{Duration: 10 * time.Minute},
+		}
+
+		job = &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-apply", Namespace: "default", CreationTimestamp: started},
+			Status:     batchv1.JobStatus{Active: 1},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(batchv1.AddToScheme(scheme)).To(Succeed())
+
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+	})
+
+	It("should set the deadline of the jobs from the timeout of their phase", func() {
+		plan, err := t.GetJobSpecForRun(PlanJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*plan.Spec.ActiveDeadlineSeconds).To(Equal(int64(1800)))
+
+		drift, err := t.GetJobSpecForRun(DriftJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*drift.Spec.ActiveDeadlineSeconds).To(Equal(int64(1800)))
+
+		apply, err := t.GetJobSpecForRun(ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*apply.Spec.ActiveDeadlineSeconds).To(Equal(int64(90)))
+
+		t.Spec.Timeouts = nil
+
+		destroy, err := t.GetJobSpecForRun(DestroyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(destroy.Spec.ActiveDeadlineSeconds).To(BeNil())
+	})
+
+	It("should report a job that exceeded its deadline", func() {
+		deadline := int64(90)
+		job.Spec.ActiveDeadlineSeconds = &deadline
+		job.Status = batchv1.JobStatus{
+			Failed: 1,
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: batchv1.JobReasonDeadlineExceeded},
+			},
+		}
+
+		reason, err := t.CheckJobTimeouts(ctx, c, job, ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("apply job 'app-apply' exceeded its timeout of 1m30s"))
+	})
+
+	It("should stop a job whose pod is unschedulable past the pending timeout", func() {
+		Expect(c.Create(ctx, job)).To(Succeed())
+		Expect(c.Create(ctx, newPod(corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable", Message: "0/3 nodes are available"},
+			},
+		}))).To(Succeed())
+
+		reason, err := t.CheckJobTimeouts(ctx, c, job, ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("pod 'app-apply-pod' was pending for more than 10m0s, it is Unschedulable (0/3 nodes are available)"))
+
+		stopped := &batchv1.Job{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(job), stopped)).To(Succeed())
+		Expect(*stopped.Spec.Suspend).To(BeTrue())
+
+		reason, err = t.CheckJobTimeouts(ctx, c, stopped, ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(ContainSubstring("Unschedulable"))
+	})
+
+	It("should report the container that can't start past the pending timeout", func() {
+		Expect(c.Create(ctx, job)).To(Succeed())
+		Expect(c.Create(ctx, newPod(corev1.PodStatus{
+			Phase: corev1.PodPending,
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "busybox", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: started}}},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: runnerContainerName, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			},
+		}))).To(Succeed())
+
+		reason, err := t.CheckJobTimeouts(ctx, c, job, ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("pod 'app-apply-pod' was pending for more than 10m0s, container 'terraform' is in ImagePullBackOff"))
+	})
+
+	It("should stop a job whose init container runs past the init timeout", func() {
+		t.Spec.Timeouts.Pending = nil
+
+		Expect(c.Create(ctx, job)).To(Succeed())
+		Expect(c.Create(ctx, newPod(corev1.PodStatus{
+			Phase: corev1.PodPending,
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "busybox", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: started}}},
+			},
+		}))).To(Succeed())
+
+		reason, err := t.CheckJobTimeouts(ctx, c, job, ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("init container 'busybox' of pod 'app-apply-pod' didn't complete in 5m0s"))
+	})
+
+	It("should report a job that created no pod past the pending timeout", func() {
+		job.Status.Active = 0
+		Expect(c.Create(ctx, job)).To(Succeed())
+
+		reason, err := t.CheckJobTimeouts(ctx, c, job, ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("job 'app-apply' created no pod in 10m0s, check the events of the job"))
+	})
+
+	It("should not report a pod within its timeouts", func() {
+		t.Spec.Timeouts.Pending = &metav1.Duration{Duration: time.Hour}
+
+		Expect(c.Create(ctx, job)).To(Succeed())
+		Expect(c.Create(ctx, newPod(corev1.PodStatus{Phase: corev1.PodPending}))).To(Succeed())
+
+		reason, err := t.CheckJobTimeouts(ctx, c, job, ApplyJob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(BeEmpty())
+
+		stopped := &batchv1.Job{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(job), stopped)).To(Succeed())
+		Expect(stopped.Spec.Suspend).To(BeNil())
+	})
+})
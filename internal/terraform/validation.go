@@ -0,0 +1,1000 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	"github.com/rinswind/terraform-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reservedVolumeNames are the volume names used by the workflow/run job
+// that can't be used as variable file keys
+var reservedVolumeNames = map[string]bool{
+	tfProjectVolumeName:            true,
+	tfProviderCacheVolumeName:      true,
+	gitSSHKeyVolumeName:            true,
+	knownHostsVolumeName:           true,
+	extraFilesVolumeName:           true,
+	gitSSHConfigVolumeName:         true,
+	backendConfigVolumeName:        true,
+	backendStateVolumeName:         true,
+	serviceAccountTokensVolumeName: true,
+	engineBinariesVolumeName:       true,
+}
+
+// Validate validates the spec of the Terraform object and returns the invalid fields
+func (t *TerraformManipulator) Validate() field.ErrorList {
+	errs := field.ErrorList{}
+
+	specPath := field.NewPath("spec")
+
+	if t.getTerraformVersion() == "" && t.getEngineType() != v1alpha1.EngineOpenTofu {
+		errs = append(errs, field.Required(specPath.Child("terraformVersion"), "terraform version is required when no default version is configured"))
+	}
+
+	if t.Spec.Module == nil && len(t.Spec.Modules) == 0 {
+		errs = append(errs, field.Required(specPath.Child("module"), "either module or modules is required"))
+	}
+
+	if t.Spec.Module != nil {
+		errs = append(errs, validateModuleSource(specPath.Child("module"), *t.Spec.Module)...)
+		errs = append(errs, t.validateModuleProviders(specPath.Child("module", "providers"), *t.Spec.Module)...)
+	}
+
+	errs = append(errs, t.validateEngine(specPath.Child("engine"))...)
+	errs = append(errs, t.validateEngineBinaries(specPath.Child("engine"))...)
+	errs = append(errs, t.validateHCLConfigs(specPath)...)
+	errs = append(errs, t.validateTypedBackend(specPath.Child("typedBackend"))...)
+	errs = append(errs, t.validateTypedProviders(specPath.Child("typedProviders"))...)
+	errs = append(errs, t.validateModules(specPath.Child("modules"))...)
+	errs = append(errs, t.validateDependsOn(specPath.Child("dependsOn"))...)
+	errs = append(errs, t.validateVariables(specPath.Child("variables"))...)
+	errs = append(errs, t.validateVariableFiles(specPath.Child("variableFiles"))...)
+	errs = append(errs, t.validateExtraFiles(specPath.Child("extraFiles"))...)
+	errs = append(errs, t.validateOutputs(specPath.Child("outputs"))...)
+	errs = append(errs, t.validateOutputGrants(specPath.Child("outputGrants"))...)
+	errs = append(errs, t.validateGitSSHKey(specPath.Child("gitSSHKey"))...)
+	errs = append(errs, t.validateGitHTTPSCredentials(specPath.Child("gitHTTPSCredentials"))...)
+	errs = append(errs, t.validateCLIConfig(specPath.Child("cliConfig"))...)
+	errs = append(errs, t.validateRunner(specPath.Child("runner"))...)
+	errs = append(errs, t.validateServiceAccount(specPath)...)
+	errs = append(errs, t.validateTimeouts(specPath.Child("timeouts"))...)
+
+	return errs
+}
+
+// validateHCLConfigs validates that the backend and providers configurations only hold the expected blocks,
+// the configurations may hold credentials so their values are omitted from the errors
+func (t *TerraformManipulator) validateHCLConfigs(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if t.Spec.Backend != "" {
+		if err := checkHCLBlocks(t.Spec.Backend, "backend"); err != nil {
+			errs = append(errs, field.Invalid(path.Child("backend"), field.OmitValueType{}, err.Error()))
+		}
+	}
+
+	if t.Spec.ProvidersConfig != "" {
+		if err := checkHCLBlocks(t.Spec.ProvidersConfig, "terraform", "provider"); err != nil {
+			errs = append(errs, field.Invalid(path.Child("providersConfig"), field.OmitValueType{}, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// validateTypedBackend validates the type and the settings of the typed backend, the sensitive settings
+// must be read from secrets so they are rejected in plain text
+func (t *TerraformManipulator) validateTypedBackend(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	backend := t.Spec.TypedBackend
+	if backend == nil {
+		return errs
+	}
+
+	if t.Spec.Backend != "" {
+		errs = append(errs, field.Forbidden(path, "the typed backend can't be combined with the backend configuration"))
+	}
+
+	_, supported := sensitiveBackendSettings[backend.Type]
+	supported = supported || backend.Type == v1alpha1.BackendLocal
+
+	if backend.Type == "" {
+		errs = append(errs, field.Required(path.Child("type"), "backend type is required"))
+	} else if !supported {
+		errs = append(errs, field.NotSupported(path.Child("type"), backend.Type, []v1alpha1.BackendType{
+			v1alpha1.BackendKubernetes, v1alpha1.BackendS3, v1alpha1.BackendGCS,
+			v1alpha1.BackendAzureRM, v1alpha1.BackendHTTP, v1alpha1.BackendLocal,
+		}))
+	}
+
+	for key := range backend.Settings {
+		keyPath := path.Child("settings").Key(key)
+
+		if !identifierRegex.MatchString(key) {
+			errs = append(errs, field.Invalid(keyPath, key, "must be a valid Terraform identifier"))
+		} else if slices.Contains(sensitiveBackendSettings[backend.Type], key) {
+			errs = append(errs, field.Forbidden(keyPath, "the setting is sensitive, it must be set in a configFrom secret"))
+		}
+	}
+
+	for i, c := range backend.ConfigFrom {
+		if c.Name == "" || c.Key == "" {
+			errs = append(errs, field.Required(path.Child("configFrom").Index(i), "the name and key of the secret are required"))
+		}
+	}
+
+	pvcPath := path.Child("persistentVolumeClaim")
+
+	if backend.Type == v1alpha1.BackendLocal && (backend.PersistentVolumeClaim == nil || backend.PersistentVolumeClaim.ClaimName == "") {
+		errs = append(errs, field.Required(pvcPath.Child("claimName"), "the local backend requires a persistent volume claim to keep the state"))
+	}
+
+	if backend.Type != v1alpha1.BackendLocal && backend.PersistentVolumeClaim != nil {
+		errs = append(errs, field.Forbidden(pvcPath, "persistentVolumeClaim only applies to the local backend"))
+	}
+
+	return errs
+}
+
+// validateTypedProviders validates the names, the requirements and the configurations of the typed providers,
+// the configurations may hold credentials so their values are omitted from the errors
+func (t *TerraformManipulator) validateTypedProviders(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if len(t.Spec.TypedProviders) > 0 && t.Spec.ProvidersConfig != "" {
+		errs = append(errs, field.Forbidden(path, "typed providers can't be combined with the providers configuration"))
+	}
+
+	addresses := map[string]bool{}
+	requirements := map[string]v1alpha1.Provider{}
+
+	for i, p := range t.Spec.TypedProviders {
+		providerPath := path.Index(i)
+
+		if p.Name == "" {
+			errs = append(errs, field.Required(providerPath.Child("name"), "provider name is required"))
+		} else if !identifierRegex.MatchString(p.Name) {
+			errs = append(errs, field.Invalid(providerPath.Child("name"), p.Name, "must be a valid Terraform identifier"))
+		}
+
+		if p.Alias != "" && !identifierRegex.MatchString(p.Alias) {
+			errs = append(errs, field.Invalid(providerPath.Child("alias"), p.Alias, "must be a valid Terraform identifier"))
+		}
+
+		if address := getProviderAddress(p); addresses[address] {
+			errs = append(errs, field.Duplicate(providerPath, address))
+		} else {
+			addresses[address] = true
+		}
+
+		required := requirements[p.Name]
+		if p.Source != "" && required.Source != "" && p.Source != required.Source {
+			errs = append(errs, field.Invalid(providerPath.Child("source"), p.Source, "must be the source of the other configurations of the provider"))
+		}
+		if p.Version != "" && required.Version != "" && p.Version != required.Version {
+			errs = append(errs, field.Invalid(providerPath.Child("version"), p.Version, "must be the version of the other configurations of the provider"))
+		}
+		if p.Source != "" {
+			required.Source = p.Source
+		}
+		if p.Version != "" {
+			required.Version = p.Version
+		}
+		requirements[p.Name] = required
+
+		// the arguments set in both config and configFrom are reported on configFrom
+		if _, err := getProviderConfig(v1alpha1.Provider{Name: p.Name, Alias: p.Alias, Config: p.Config}); err != nil {
+			errs = append(errs, field.Invalid(providerPath.Child("config"), field.OmitValueType{}, err.Error()))
+		}
+
+		arguments := map[string]bool{}
+
+		for j, a := range p.ConfigFrom {
+			argumentPath := providerPath.Child("configFrom").Index(j)
+
+			if a.Name == "" {
+				errs = append(errs, field.Required(argumentPath.Child("name"), "argument name is required"))
+			} else if arguments[a.Name] {
+				errs = append(errs, field.Duplicate(argumentPath.Child("name"), a.Name))
+			} else if !identifierRegex.MatchString(a.Name) || a.Name == "alias" {
+				errs = append(errs, field.Invalid(argumentPath.Child("name"), a.Name, "must be a valid Terraform identifier other than alias"))
+			} else if t.declaresVariable(getProviderVariableName(p, a.Name)) {
+				errs = append(errs, field.Invalid(argumentPath.Child("name"), a.Name,
+					fmt.Sprintf("the variable '%s' of the argument is already declared in spec.variables", getProviderVariableName(p, a.Name))))
+			} else if hasProviderArgument(p, a.Name) {
+				errs = append(errs, field.Invalid(argumentPath.Child("name"), a.Name, "the argument is already set in config"))
+			}
+			arguments[a.Name] = true
+
+			if a.SecretKeyRef.Name == "" || a.SecretKeyRef.Key == "" {
+				errs = append(errs, field.Required(argumentPath.Child("secretKeyRef"), "the name and key of the secret are required"))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateModuleProviders validates that the provider configurations passed to a module are typed providers
+func (t *TerraformManipulator) validateModuleProviders(path *field.Path, m v1alpha1.Module) field.ErrorList {
+	errs := field.ErrorList{}
+	names := map[string]bool{}
+
+	for i, p := range m.Providers {
+		providerPath := path.Index(i)
+
+		if p.Name == "" {
+			errs = append(errs, field.Required(providerPath.Child("name"), "provider name is required"))
+		} else if names[p.Name] {
+			errs = append(errs, field.Duplicate(providerPath.Child("name"), p.Name))
+		} else if !providerConfigRegex.MatchString(p.Name) {
+			errs = append(errs, field.Invalid(providerPath.Child("name"), p.Name, "must be a provider configuration address, `<name>` or `<name>.<alias>`"))
+		}
+		names[p.Name] = true
+
+		if !t.declaresProvider(p.Provider) {
+			errs = append(errs, field.Invalid(providerPath.Child("provider"), p.Provider,
+				"must be the address of a provider listed in spec.typedProviders, `<name>` or `<name>.<alias>`"))
+		}
+	}
+
+	return errs
+}
+
+// validateModules validates the named module calls of the workflow/run and the wiring of their inputs
+func (t *TerraformManipulator) validateModules(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	names := map[string]bool{}
+
+	for i, m := range t.Spec.Modules {
+		modulePath := path.Index(i)
+
+		if m.Name == "" {
+			errs = append(errs, field.Required(modulePath.Child("name"), "module name is required"))
+		} else if names[m.Name] {
+			errs = append(errs, field.Duplicate(modulePath.Child("name"), m.Name))
+		} else if !identifierRegex.MatchString(m.Name) {
+			errs = append(errs, field.Invalid(modulePath.Child("name"), m.Name, "must be a valid Terraform identifier"))
+		} else if m.Name == moduleName {
+			errs = append(errs, field.Invalid(modulePath.Child("name"), m.Name, "the name is reserved for the call of spec.module"))
+		}
+		names[m.Name] = true
+
+		errs = append(errs, validateModuleSource(modulePath, m.Module)...)
+		errs = append(errs, t.validateModuleProviders(modulePath.Child("providers"), m.Module)...)
+
+		inputs := map[string]bool{}
+
+		for j, in := range m.Inputs {
+			inputPath := modulePath.Child("inputs").Index(j)
+
+			if in.Name == "" {
+				errs = append(errs, field.Required(inputPath.Child("name"), "input name is required"))
+			} else if inputs[in.Name] {
+				errs = append(errs, field.Duplicate(inputPath.Child("name"), in.Name))
+			} else if !identifierRegex.MatchString(in.Name) || reservedVariableNames[in.Name] {
+				errs = append(errs, field.Invalid(inputPath.Child("name"), in.Name,
+					"must be a valid Terraform identifier and not a reserved module argument"))
+			}
+			inputs[in.Name] = true
+
+			if (in.Variable == "") == (in.ModuleOutput == nil) {
+				errs = append(errs, field.Invalid(inputPath, in.Name, "exactly one of variable or moduleOutput is required"))
+				continue
+			}
+
+			if in.Variable != "" && !t.declaresVariable(in.Variable) {
+				errs = append(errs, field.Invalid(inputPath.Child("variable"), in.Variable,
+					"must be the key of a variable listed in spec.variables that isn't an environment variable"))
+			}
+
+			if in.ModuleOutput == nil {
+				continue
+			}
+
+			refPath := inputPath.Child("moduleOutput")
+
+			if !t.callsModule(in.ModuleOutput.Module) || in.ModuleOutput.Module == m.Name {
+				errs = append(errs, field.Invalid(refPath.Child("module"), in.ModuleOutput.Module,
+					"must be the name of another module listed in spec.modules"))
+			}
+
+			if !identifierRegex.MatchString(in.ModuleOutput.Output) {
+				errs = append(errs, field.Invalid(refPath.Child("output"), in.ModuleOutput.Output, "must be a valid Terraform identifier"))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateModuleSource validates that a module is either sourced from an address (registry, git, ...)
+// or from the files of a local module in configmaps & secrets
+func validateModuleSource(path *field.Path, m v1alpha1.Module) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if m.SourceFrom == nil {
+		if m.Source == "" {
+			errs = append(errs, field.Required(path.Child("source"), "module source is required unless sourceFrom is set"))
+		}
+		return errs
+	}
+
+	sourceFromPath := path.Child("sourceFrom")
+
+	if m.Source != "" {
+		errs = append(errs, field.Invalid(path.Child("source"), m.Source, "can't be combined with sourceFrom"))
+	}
+
+	if m.Version != "" {
+		errs = append(errs, field.Invalid(path.Child("version"), m.Version, "a local module from sourceFrom has no version"))
+	}
+
+	if len(m.SourceFrom.ConfigMaps) == 0 && len(m.SourceFrom.Secrets) == 0 && len(m.SourceFrom.Archive) == 0 {
+		errs = append(errs, field.Required(sourceFromPath, "at least one configmap, secret or archive part is required"))
+	}
+
+	for i, part := range m.SourceFrom.Archive {
+		if (part.ConfigMapKeyRef == nil) == (part.SecretKeyRef == nil) {
+			errs = append(errs, field.Invalid(sourceFromPath.Child("archive").Index(i), field.OmitValueType{},
+				"exactly one of configMapKeyRef or secretKeyRef is required"))
+		}
+	}
+
+	return errs
+}
+
+// validateDependsOn validates the dependencies of the workflow/run
+func (t *TerraformManipulator) validateDependsOn(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	for i, d := range t.Spec.DependsOn {
+		if d == nil {
+			errs = append(errs, field.Required(path.Index(i), "dependency must not be empty"))
+			continue
+		}
+
+		if d.Name == "" {
+			errs = append(errs, field.Required(path.Index(i).Child("name"), "dependency name is required"))
+		}
+
+		if d.Name == t.Name && (d.Namespace == "" || d.Namespace == t.Namespace) {
+			errs = append(errs, field.Invalid(path.Index(i).Child("name"), d.Name, "a run can't depend on itself"))
+		}
+	}
+
+	return errs
+}
+
+// validateVariables validates the variables of the workflow/run
+func (t *TerraformManipulator) validateVariables(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	keys := map[string]bool{}
+
+	for i, v := range t.Spec.Variables {
+		varPath := path.Index(i)
+
+		if v.Key == "" {
+			errs = append(errs, field.Required(varPath.Child("key"), "variable key is required"))
+		} else if keys[v.Key] {
+			errs = append(errs, field.Duplicate(varPath.Child("key"), v.Key))
+		}
+		keys[v.Key] = true
+
+		if v.Key != "" && !v.EnvironmentVariable && (!identifierRegex.MatchString(v.Key) || reservedVariableNames[v.Key]) {
+			errs = append(errs, field.Invalid(varPath.Child("key"), v.Key,
+				"must be a valid Terraform identifier and not a reserved module argument"))
+		}
+
+		errs = append(errs, validateVariableDeclaration(varPath, v)...)
+
+		if v.DependencyRef == nil {
+			continue
+		}
+
+		refPath := varPath.Child("dependencyRef")
+
+		if v.DependencyRef.Key == "" {
+			errs = append(errs, field.Required(refPath.Child("key"), "dependency output key is required"))
+		}
+
+		if !t.dependsOn(v.DependencyRef.Name, v.DependencyRef.Namespace) {
+			errs = append(errs, field.Invalid(refPath.Child("name"), v.DependencyRef.Name,
+				"must be the name of a dependency listed in spec.dependsOn within the referenced namespace"))
+		}
+	}
+
+	return errs
+}
+
+// validateVariableDeclaration validates the declaration (type, sensitive, nullable) and the structured value of a variable
+func validateVariableDeclaration(path *field.Path, v v1alpha1.Variable) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if v.EnvironmentVariable {
+		if v.Type != "" || v.Sensitive || v.Nullable != nil || v.JSONValue != nil {
+			errs = append(errs, field.Invalid(path.Child("environmentVariable"), v.EnvironmentVariable,
+				"type, sensitive, nullable and jsonValue only apply to Terraform variables"))
+		}
+		return errs
+	}
+
+	if v.Type != "" {
+		if err := checkHCLExpression(v.Type); err != nil {
+			errs = append(errs, field.Invalid(path.Child("type"), v.Type, err.Error()))
+		}
+	}
+
+	if v.JSONValue != nil && (v.Value != "" || v.ValueFrom != nil || v.DependencyRef != nil) {
+		errs = append(errs, field.Invalid(path.Child("jsonValue"), field.OmitValueType{},
+			"can't be combined with value, valueFrom or dependencyRef"))
+	}
+
+	return errs
+}
+
+// validateVariableFiles validates the variable files of the workflow/run, the keys are used as volume names
+func (t *TerraformManipulator) validateVariableFiles(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	keys := map[string]bool{}
+
+	for i, file := range t.Spec.VariableFiles {
+		keyPath := path.Index(i).Child("key")
+
+		if file.ValueFrom == nil {
+			errs = append(errs, field.Required(path.Index(i).Child("valueFrom"), "variable file source is required"))
+		}
+
+		if file.Key == "" {
+			errs = append(errs, field.Required(keyPath, "variable file key is required"))
+			continue
+		}
+
+		if keys[file.Key] {
+			errs = append(errs, field.Duplicate(keyPath, file.Key))
+		}
+		keys[file.Key] = true
+
+		if reservedVolumeNames[file.Key] || strings.HasPrefix(file.Key, moduleSourcesVolumePrefix+"-") {
+			errs = append(errs, field.Invalid(keyPath, file.Key, "the key is reserved for an internal volume"))
+		}
+
+		for _, msg := range validation.IsDNS1123Label(file.Key) {
+			errs = append(errs, field.Invalid(keyPath, file.Key, msg))
+		}
+	}
+
+	return errs
+}
+
+// validateExtraFiles validates the additional Terraform files of the workflow/run, the inline
+// files must hold complete HCL blocks or a JSON object
+func (t *TerraformManipulator) validateExtraFiles(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	names := map[string]bool{}
+
+	for i, f := range t.Spec.ExtraFiles {
+		filePath := path.Index(i)
+
+		validName := false
+
+		if f.Name == "" {
+			errs = append(errs, field.Required(filePath.Child("name"), "file name is required"))
+		} else if names[f.Name] {
+			errs = append(errs, field.Duplicate(filePath.Child("name"), f.Name))
+		} else if err := checkExtraFileName(f.Name); err != nil {
+			errs = append(errs, field.Invalid(filePath.Child("name"), f.Name, err.Error()))
+		} else {
+			validName = true
+		}
+		names[f.Name] = true
+
+		if f.ValueFrom != nil {
+			if f.Content != "" {
+				errs = append(errs, field.Invalid(filePath.Child("content"), field.OmitValueType{}, "can't be combined with valueFrom"))
+			}
+
+			if (f.ValueFrom.ConfigMapKeyRef == nil) == (f.ValueFrom.SecretKeyRef == nil) {
+				errs = append(errs, field.Invalid(filePath.Child("valueFrom"), field.OmitValueType{},
+					"exactly one of configMapKeyRef or secretKeyRef is required"))
+			}
+
+			continue
+		}
+
+		// the syntax of the content depends on the file extension
+		if !validName {
+			continue
+		}
+
+		if strings.HasSuffix(f.Name, ".tf.json") {
+			if err := checkTerraformFile(f.Name, f.Content); err != nil {
+				errs = append(errs, field.Invalid(filePath.Child("content"), field.OmitValueType{}, err.Error()))
+			}
+		} else if err := checkHCLBlocks(f.Content); err != nil {
+			errs = append(errs, field.Invalid(filePath.Child("content"), field.OmitValueType{}, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// validateOutputs validates the outputs of the workflow/run
+func (t *TerraformManipulator) validateOutputs(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	keys := map[string]bool{}
+
+	for i, o := range t.Spec.Outputs {
+		outputPath := path.Index(i)
+
+		if o == nil {
+			errs = append(errs, field.Required(outputPath, "output must not be empty"))
+			continue
+		}
+
+		if o.Key == "" {
+			errs = append(errs, field.Required(outputPath.Child("key"), "output key is required"))
+		} else if keys[o.Key] {
+			errs = append(errs, field.Duplicate(outputPath.Child("key"), o.Key))
+		}
+		keys[o.Key] = true
+
+		if o.Key != "" && !identifierRegex.MatchString(o.Key) {
+			errs = append(errs, field.Invalid(outputPath.Child("key"), o.Key, "must be a valid Terraform identifier"))
+		}
+
+		if o.ModuleOutputName == "" {
+			errs = append(errs, field.Required(outputPath.Child("moduleOutputName"), "module output name is required"))
+		} else if !identifierRegex.MatchString(o.ModuleOutputName) {
+			errs = append(errs, field.Invalid(outputPath.Child("moduleOutputName"), o.ModuleOutputName, "must be a valid Terraform identifier"))
+		}
+
+		if o.Module == "" && t.Spec.Module == nil && len(t.Spec.Modules) > 0 {
+			errs = append(errs, field.Required(outputPath.Child("module"), "module is required when spec.module is not set"))
+		} else if o.Module != "" && !t.callsModule(o.Module) && (o.Module != moduleName || t.Spec.Module == nil) {
+			errs = append(errs, field.Invalid(outputPath.Child("module"), o.Module, "must be the name of a module listed in spec.modules"))
+		}
+	}
+
+	return errs
+}
+
+// validateOutputGrants validates the namespaces the outputs of the workflow/run are granted to
+func (t *TerraformManipulator) validateOutputGrants(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	for i, g := range t.Spec.OutputGrants {
+		namespacePath := path.Index(i).Child("namespace")
+
+		if g.Namespace == "" {
+			errs = append(errs, field.Required(namespacePath, "namespace is required"))
+			continue
+		}
+
+		for _, msg := range validation.IsDNS1123Label(g.Namespace) {
+			errs = append(errs, field.Invalid(namespacePath, g.Namespace, msg))
+		}
+	}
+
+	return errs
+}
+
+// validateGitSSHKey validates the SSH keys, ssh_config and known_hosts of the workflow/run
+func (t *TerraformManipulator) validateGitSSHKey(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	key := t.Spec.GitSSHKey
+	if key == nil {
+		return errs
+	}
+
+	if key.ValueFrom == nil && !t.hasGitSSHConfig() {
+		errs = append(errs, field.Required(path, "at least one of valueFrom, keys, configFrom or knownHostsFrom is required"))
+	}
+
+	names := map[string]bool{}
+
+	for i, k := range key.Keys {
+		namePath := path.Child("keys").Index(i).Child("name")
+
+		if k.SecretKeyRef == nil {
+			errs = append(errs, field.Required(path.Child("keys").Index(i).Child("secretKeyRef"), "the secret of the key is required"))
+		}
+
+		if k.Name == "" {
+			errs = append(errs, field.Required(namePath, "key name is required"))
+			continue
+		}
+
+		if names[k.Name] {
+			errs = append(errs, field.Duplicate(namePath, k.Name))
+		}
+		names[k.Name] = true
+
+		if k.Name == sshConfigFileName || k.Name == sshKnownHostsFileName {
+			errs = append(errs, field.Invalid(namePath, k.Name, "the name is reserved for the ssh_config and known_hosts files"))
+		}
+
+		for _, msg := range validation.IsConfigMapKey(k.Name) {
+			errs = append(errs, field.Invalid(namePath, k.Name, msg))
+		}
+	}
+
+	if key.ConfigFrom != nil && (key.ConfigFrom.ConfigMapKeyRef == nil) == (key.ConfigFrom.SecretKeyRef == nil) {
+		errs = append(errs, field.Invalid(path.Child("configFrom"), field.OmitValueType{},
+			"exactly one of configMapKeyRef or secretKeyRef is required"))
+	}
+
+	if key.KnownHostsFrom != nil && (key.KnownHostsFrom.ConfigMapKeyRef == nil) == (key.KnownHostsFrom.SecretKeyRef == nil) {
+		errs = append(errs, field.Invalid(path.Child("knownHostsFrom"), field.OmitValueType{},
+			"exactly one of configMapKeyRef or secretKeyRef is required"))
+	}
+
+	return errs
+}
+
+// validateGitHTTPSCredentials validates the HTTPS credentials of the git hosts of the workflow/run
+func (t *TerraformManipulator) validateGitHTTPSCredentials(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	urls := map[string]bool{}
+
+	for i, c := range t.Spec.GitHTTPSCredentials {
+		credentialPath := path.Index(i)
+		urlPath := credentialPath.Child("url")
+
+		if c.URL == "" {
+			errs = append(errs, field.Required(urlPath, "url is required"))
+		} else if urls[c.URL] {
+			errs = append(errs, field.Duplicate(urlPath, c.URL))
+		} else if u, err := url.Parse(c.URL); err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil ||
+			u.RawQuery != "" || u.Fragment != "" || strings.ContainsAny(c.URL, " \t\n") {
+			errs = append(errs, field.Invalid(urlPath, field.OmitValueType{},
+				"must be an https URL of a git host or repository path without credentials, e.g. https://github.com"))
+		}
+		urls[c.URL] = true
+
+		if (c.Username == "") == (c.UsernameFrom == nil) {
+			errs = append(errs, field.Invalid(credentialPath.Child("username"), field.OmitValueType{},
+				"exactly one of username or usernameFrom is required"))
+		}
+
+		if c.PasswordFrom == nil {
+			errs = append(errs, field.Required(credentialPath.Child("passwordFrom"), "the secret of the password or token is required"))
+		}
+	}
+
+	return errs
+}
+
+// validateCLIConfig validates the registry credentials and the provider installation methods of the workflow/run
+func (t *TerraformManipulator) validateCLIConfig(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if t.Spec.CLIConfig == nil {
+		return errs
+	}
+
+	hosts := map[string]bool{}
+
+	for i, c := range t.Spec.CLIConfig.Credentials {
+		hostPath := path.Child("credentials").Index(i).Child("host")
+
+		if c.Host == "" {
+			errs = append(errs, field.Required(hostPath, "host is required"))
+		} else if hosts[c.Host] {
+			errs = append(errs, field.Duplicate(hostPath, c.Host))
+		} else if err := checkCLICredentialHost(c.Host); err != nil {
+			errs = append(errs, field.Invalid(hostPath, c.Host, err.Error()))
+		}
+		hosts[c.Host] = true
+
+		if c.TokenFrom == nil {
+			errs = append(errs, field.Required(path.Child("credentials").Index(i).Child("tokenFrom"), "the secret of the API token is required"))
+		}
+	}
+
+	for i, m := range t.Spec.CLIConfig.ProviderInstallation {
+		if err := checkProviderInstallationMethod(m); err != nil {
+			errs = append(errs, field.Invalid(path.Child("providerInstallation").Index(i), field.OmitValueType{}, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// validateEngine validates that the engine has the version it requires, that its version and version constraint
+// are valid and that the encryption is only set for OpenTofu
+func (t *TerraformManipulator) validateEngine(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	engine := t.Spec.Engine
+	if engine == nil {
+		return errs
+	}
+
+	if engine.Version == "" && (engine.Type == v1alpha1.EngineOpenTofu || engine.Type == v1alpha1.EngineTerragrunt) {
+		errs = append(errs, field.Required(path.Child("version"), fmt.Sprintf("the version of the %s engine is required", engine.Type)))
+	}
+
+	if engine.Version != "" && !engineVersionRegex.MatchString(engine.Version) {
+		errs = append(errs, field.Invalid(path.Child("version"), engine.Version, "must be an exact version, e.g. 1.8.2"))
+	}
+
+	if engine.VersionConstraint != "" && !versionConstraintRegex.MatchString(engine.VersionConstraint) {
+		errs = append(errs, field.Invalid(path.Child("versionConstraint"), engine.VersionConstraint, "must be a version constraint, e.g. ~> 1.8"))
+	}
+
+	if engine.Encryption != nil && engine.Type != v1alpha1.EngineOpenTofu {
+		errs = append(errs, field.Forbidden(path.Child("encryption"), "encryption only applies to the opentofu engine"))
+	}
+
+	return errs
+}
+
+// validateEngineBinaries validates that the engine binaries of the workflow/run are available in the runner images
+// or the binary cache of the operator config, if it has any
+func (t *TerraformManipulator) validateEngineBinaries(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if utils.Env.EngineBinaries == nil {
+		return errs
+	}
+
+	for _, r := range t.getEngineRequirements() {
+		if r.version == "" {
+			continue
+		}
+
+		if _, _, err := resolveEngineBinary(r.engine, r.version); err != nil {
+			errs = append(errs, field.NotSupported(r.path, r.version, getAvailableEngineVersions(r.engine)))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if _, err := t.getEngineBinaries(); err != nil {
+		errs = append(errs, field.Invalid(path, string(t.getEngineType()), err.Error()))
+	}
+
+	return errs
+}
+
+// validateRunner validates that the pod template of the workflow/run can be merged into the pods of its jobs
+func (t *TerraformManipulator) validateRunner(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if t.Spec.Runner == nil || t.Spec.Runner.PodTemplate == nil {
+		return errs
+	}
+
+	// the pod template is checked against the operator owned environment variables & volumes of the runner
+	template := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: runnerContainerName, Env: t.getEnvVariables(ApplyJob), VolumeMounts: t.getJobVolumeMounts()},
+			},
+			Volumes:       t.getJobVolumes(),
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
+	}
+
+	if err := t.applyRunnerPodTemplate(template); err != nil {
+		errs = append(errs, field.Invalid(path.Child("podTemplate"), field.OmitValueType{}, err.Error()))
+	}
+
+	return errs
+}
+
+// validateServiceAccount validates the service account of the spec, the annotations of the generated
+// service account and the projected service account tokens
+func (t *TerraformManipulator) validateServiceAccount(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if t.Spec.ServiceAccountName != "" {
+		if t.Spec.ServiceAccount != nil {
+			errs = append(errs, field.Forbidden(path.Child("serviceAccount"), "serviceAccount can't be combined with serviceAccountName"))
+		}
+
+		for _, msg := range validation.IsDNS1123Subdomain(t.Spec.ServiceAccountName) {
+			errs = append(errs, field.Invalid(path.Child("serviceAccountName"), t.Spec.ServiceAccountName, msg))
+		}
+	}
+
+	if t.Spec.ServiceAccount != nil {
+		errs = append(errs, apivalidation.ValidateAnnotations(t.Spec.ServiceAccount.Annotations, path.Child("serviceAccount", "annotations"))...)
+	}
+
+	paths := map[string]bool{}
+
+	for i, token := range t.Spec.ServiceAccountTokens {
+		tokenPath := path.Child("serviceAccountTokens").Index(i)
+
+		if token.Path == "" {
+			errs = append(errs, field.Required(tokenPath.Child("path"), "token path is required"))
+		} else if paths[token.Path] {
+			errs = append(errs, field.Duplicate(tokenPath.Child("path"), token.Path))
+		} else if msgs := validation.IsConfigMapKey(token.Path); len(msgs) > 0 {
+			errs = append(errs, field.Invalid(tokenPath.Child("path"), token.Path, strings.Join(msgs, ", ")))
+		}
+		paths[token.Path] = true
+
+		if token.Audience == "" {
+			errs = append(errs, field.Required(tokenPath.Child("audience"), "token audience is required"))
+		}
+
+		if token.ExpirationSeconds != nil && *token.ExpirationSeconds < 600 {
+			errs = append(errs, field.Invalid(tokenPath.Child("expirationSeconds"), *token.ExpirationSeconds, "must be at least 600 seconds"))
+		}
+	}
+
+	return errs
+}
+
+// validateTimeouts validates that the timeouts of the workflow/run are at least a second, the plan and apply
+// timeouts are the deadlines of the jobs in seconds
+func (t *TerraformManipulator) validateTimeouts(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if t.Spec.Timeouts == nil {
+		return errs
+	}
+
+	timeouts := []struct {
+		name    string
+		timeout *metav1.Duration
+	}{
+		{"initContainer", t.Spec.Timeouts.InitContainer},
+		{"plan", t.Spec.Timeouts.Plan},
+		{"apply", t.Spec.Timeouts.Apply},
+		{"pending", t.Spec.Timeouts.Pending},
+	}
+
+	for _, tm := range timeouts {
+		if tm.timeout != nil && tm.timeout.Duration < time.Second {
+			errs = append(errs, field.Invalid(path.Child(tm.name), tm.timeout.Duration.String(), "must be at least 1s"))
+		}
+	}
+
+	return errs
+}
+
+// dependsOn evaluates if the workflow/run depends on a run with the given name and namespace,
+// an empty namespace is the namespace of the workflow/run
+func (t *TerraformManipulator) dependsOn(name string, namespace string) bool {
+	if namespace == "" {
+		namespace = t.Namespace
+	}
+
+	for _, d := range t.Spec.DependsOn {
+		if d == nil || d.Name != name {
+			continue
+		}
+
+		if d.Namespace == namespace || (d.Namespace == "" && namespace == t.Namespace) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// declaresVariable evaluates if the workflow/run declares a Terraform variable with the given key
+func (t *TerraformManipulator) declaresVariable(key string) bool {
+	for _, v := range t.Spec.Variables {
+		if v.Key == key && !v.EnvironmentVariable {
+			return true
+		}
+	}
+
+	return false
+}
+
+// declaresProvider evaluates if the workflow/run has a typed provider configuration with the given address
+func (t *TerraformManipulator) declaresProvider(address string) bool {
+	for _, p := range t.Spec.TypedProviders {
+		if getProviderAddress(p) == address {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasProviderArgument evaluates if the config of a typed provider sets the given argument
+func hasProviderArgument(p v1alpha1.Provider, argument string) bool {
+	if p.Config == nil {
+		return false
+	}
+
+	config := map[string]json.RawMessage{}
+	if err := json.Unmarshal(p.Config.Raw, &config); err != nil {
+		return false
+	}
+
+	_, ok := config[argument]
+	return ok
+}
+
+// callsModule evaluates if the workflow/run has a named module call with the given name
+func (t *TerraformManipulator) callsModule(name string) bool {
+	for _, m := range t.Spec.Modules {
+		if m.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateDependencyCycle walks the dependencies of the workflow/run and returns an error
+// if they lead back to it, dependencies that don't exist yet are ignored
+func (t *TerraformManipulator) ValidateDependencyCycle(ctx context.Context, c client.Client) (*field.Error, error) {
+	self := types.NamespacedName{Namespace: t.Namespace, Name: t.Name}
+	visited := map[types.NamespacedName]bool{}
+
+	var walk func(run *v1alpha1.Terraform, path []string) ([]string, error)
+
+	walk = func(run *v1alpha1.Terraform, path []string) ([]string, error) {
+		for _, d := range run.Spec.DependsOn {
+			if d == nil {
+				continue
+			}
+
+			depName := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}
+			if depName.Namespace == "" {
+				depName.Namespace = run.Namespace
+			}
+
+			if depName == self {
+				return append(path, depName.String()), nil
+			}
+
+			if visited[depName] {
+				continue
+			}
+			visited[depName] = true
+
+			dep := &v1alpha1.Terraform{}
+
+			if err := c.Get(ctx, depName, dep); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+
+			cycle, err := walk(dep, append(path, depName.String()))
+			if cycle != nil || err != nil {
+				return cycle, err
+			}
+		}
+
+		return nil, nil
+	}
+
+	cycle, err := walk(t.Terraform, []string{self.String()})
+	if err != nil || cycle == nil {
+		return nil, err
+	}
+
+	return field.Invalid(field.NewPath("spec", "dependsOn"), t.Spec.DependsOn,
+		fmt.Sprintf("dependency cycle detected: %v", cycle)), nil
+}
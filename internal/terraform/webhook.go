@@ -0,0 +1,117 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rinswind/terraform-operator/api/v1alpha1"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// TerraformDefaulter sets the defaults of the Terraform objects when they are created, and records the
+// authenticated user approving a run when they are created or updated
+type TerraformDefaulter struct{}
+
+var _ admission.CustomDefaulter = &TerraformDefaulter{}
+
+// Default sets the defaults of a Terraform object on creation and the approver of its approval
+func (d *TerraformDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	run, ok := obj.(*v1alpha1.Terraform)
+	if !ok {
+		return fmt.Errorf("expected a Terraform object but got %T", obj)
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	t := &TerraformManipulator{Terraform: run}
+
+	var old *v1alpha1.Terraform
+
+	if req.Operation == admissionv1.Create {
+		t.SetDefaults()
+	} else {
+		old = &v1alpha1.Terraform{}
+
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return fmt.Errorf("unable to decode the Terraform object before the update: %w", err)
+		}
+	}
+
+	return t.RecordApprover(old, req.UserInfo.Username)
+}
+
+// TerraformValidator rejects Terraform objects with an invalid spec when they are created or updated
+type TerraformValidator struct {
+	Client client.Client
+}
+
+var _ admission.CustomValidator = &TerraformValidator{}
+
+// ValidateCreate validates a Terraform object on creation
+func (v *TerraformValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	run, ok := obj.(*v1alpha1.Terraform)
+	if !ok {
+		return nil, fmt.Errorf("expected a Terraform object but got %T", obj)
+	}
+
+	return nil, v.validate(ctx, run)
+}
+
+// ValidateUpdate validates a Terraform object on update, updates that leave the spec untouched
+// (e.g. finalizers, annotations) or happen during the deletion are always allowed
+func (v *TerraformValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldRun, ok := oldObj.(*v1alpha1.Terraform)
+	if !ok {
+		return nil, fmt.Errorf("expected a Terraform object but got %T", oldObj)
+	}
+
+	run, ok := newObj.(*v1alpha1.Terraform)
+	if !ok {
+		return nil, fmt.Errorf("expected a Terraform object but got %T", newObj)
+	}
+
+	if !run.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldRun.Spec, run.Spec) {
+		return nil, nil
+	}
+
+	return nil, v.validate(ctx, run)
+}
+
+// ValidateDelete allows the deletion of any Terraform object
+func (v *TerraformValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate returns an invalid error with all the field errors of the Terraform object
+func (v *TerraformValidator) validate(ctx context.Context, run *v1alpha1.Terraform) error {
+	t := &TerraformManipulator{Terraform: run}
+
+	errs := t.Validate()
+
+	// a cycle can only be detected once the dependencies themselves are valid
+	if len(errs) == 0 && len(t.Spec.DependsOn) > 0 {
+		cycleErr, err := t.ValidateDependencyCycle(ctx, v.Client)
+		if err != nil {
+			return fmt.Errorf("unable to check the dependencies for a cycle: %w", err)
+		}
+
+		if cycleErr != nil {
+			errs = append(errs, cycleErr)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errors.NewInvalid(v1alpha1.GroupVersion.WithKind("Terraform").GroupKind(), run.Name, errs)
+}
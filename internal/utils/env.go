@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"encoding/json"
 	"log"
 	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 // EnvConfig holds the environment variables information
@@ -10,7 +14,27 @@ type EnvConfig struct {
 	DockerRepository        string
 	TerraformRunnerImage    string
 	TerraformRunnerImageTag string
+	TerraformRunnerFeatures []string
 	KnownHostsConfigMapName string
+	DefaultTerraformVersion string
+	DefaultCLIConfig        string
+	EngineBinaries          *EngineBinaries
+}
+
+// EngineBinaries holds the engine binaries resolved ahead of the runs, the runner doesn't download them
+type EngineBinaries struct {
+	// Images maps the engines and their versions to runner images with the binaries installed
+	Images map[string]map[string]string `json:"images,omitempty"`
+	// Cache is a volume of engine binaries shared by the runs
+	Cache *EngineBinaryCache `json:"cache,omitempty"`
+}
+
+// EngineBinaryCache holds a volume of engine binaries laid out as `<engine>/<version>/`
+type EngineBinaryCache struct {
+	// Volume is the source of the volume, e.g. a persistent volume claim or an image
+	Volume corev1.VolumeSource `json:"volume"`
+	// Versions are the versions of each engine held by the volume
+	Versions map[string][]string `json:"versions"`
 }
 
 // Env holds the values of the environment variables
@@ -34,6 +58,33 @@ func getEnvOptional(name string) string {
 	return ""
 }
 
+// getEnvList returns the comma separated values of an optional environment variable, nil if it doesn't exist
+func getEnvList(name string) []string {
+	var values []string
+
+	for _, value := range strings.Split(getEnvOptional(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// getEnvJSON decodes an optional JSON environment variable into v and panics if it's not valid
+func getEnvJSON(name string, v interface{}) bool {
+	env, present := os.LookupEnv(name)
+	if !present || env == "" {
+		return false
+	}
+
+	if err := json.Unmarshal([]byte(env), v); err != nil {
+		log.Panicf("environment variable '%s' is not valid JSON: %v", name, err)
+	}
+
+	return true
+}
+
 // LoadEnv loads teh environment variables
 func LoadEnv() {
 	cfg := &EnvConfig{}
@@ -42,7 +93,15 @@ func LoadEnv() {
 	cfg.TerraformRunnerImage = getEnvOrPanic("TERRAFORM_RUNNER_IMAGE")
 	cfg.TerraformRunnerImageTag = getEnvOrPanic("TERRAFORM_RUNNER_IMAGE_TAG")
 	cfg.TerraformRunnerImageTag = getEnvOrPanic("TERRAFORM_RUNNER_IMAGE_TAG")
+	cfg.TerraformRunnerFeatures = getEnvList("TERRAFORM_RUNNER_FEATURES")
 	cfg.KnownHostsConfigMapName = getEnvOptional("KNOWN_HOSTS_CONFIGMAP_NAME")
+	cfg.DefaultTerraformVersion = getEnvOptional("DEFAULT_TERRAFORM_VERSION")
+	cfg.DefaultCLIConfig = getEnvOptional("DEFAULT_TERRAFORM_CLI_CONFIG")
+
+	binaries := &EngineBinaries{}
+	if getEnvJSON("ENGINE_BINARIES", binaries) {
+		cfg.EngineBinaries = binaries
+	}
 
 	Env = cfg
 }